@@ -0,0 +1,61 @@
+package nanovgo
+
+// SetBlendMode sets the blend mode of following drawing. The default is BlendNormal.
+// The blended colors are composited by SourceOver, and the composite operation is ignored while the blend mode
+// is not BlendNormal. The blend mode is a part of the render state.
+// On GL backends, each draw call with the blend mode copies the destination into a texture, so it is slower than
+// normal drawing. Overlapping parts of the same path are blended with the destination before the path is drawn.
+func (c *Context) SetBlendMode(mode BlendMode) {
+	if c.recorder != nil {
+		defer c.record(OpSetBlendMode, float32(mode))()
+	}
+	c.getState().blendMode = mode
+}
+
+// blendChannel mixes the channel of the unpremultiplied colors of the source and the destination.
+func blendChannel(mode BlendMode, s, d float32) float32 {
+	switch mode {
+	case BlendMultiply:
+		return s * d
+	case BlendScreen:
+		return s + d - s*d
+	case BlendOverlay:
+		if d <= 0.5 {
+			return 2.0 * s * d
+		}
+		return 1.0 - 2.0*(1.0-s)*(1.0-d)
+	case BlendDarken:
+		return minF(s, d)
+	case BlendLighten:
+		return maxF(s, d)
+	case BlendColorDodge:
+		if d <= 0.0 {
+			return 0.0
+		} else if s >= 1.0 {
+			return 1.0
+		}
+		return minF(1.0, d/(1.0-s))
+	case BlendDifference:
+		return absF(s - d)
+	}
+	return s
+}
+
+// blendColors mixes the premultiplied colors of the source and the destination by the blend mode, and composites
+// the result by source-over.
+func blendColors(mode BlendMode, src, dst [4]float32) [4]float32 {
+	sa, da := src[3], dst[3]
+	var result [4]float32
+	for i := 0; i < 3; i++ {
+		var s, d float32
+		if sa > 0 {
+			s = src[i] / sa
+		}
+		if da > 0 {
+			d = dst[i] / da
+		}
+		result[i] = src[i]*(1.0-da) + dst[i]*(1.0-sa) + sa*da*blendChannel(mode, s, d)
+	}
+	result[3] = sa + da*(1.0-sa)
+	return result
+}
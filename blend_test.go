@@ -0,0 +1,86 @@
+package nanovgo
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestBlendMode(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 48)
+	ctx.SetFillColor(color.RGBA{R: 128, G: 128, B: 128, A: 255})
+	ctx.Fill()
+
+	ctx.Save()
+	ctx.SetGlobalCompositeOperation(DestinationOut)
+	ctx.SetBlendMode(BlendMultiply)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 64)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+
+	ctx.SetBlendMode(BlendScreen)
+	ctx.BeginPath()
+	ctx.Rect(16, 0, 16, 16)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+
+	ctx.SetBlendMode(BlendDifference)
+	ctx.BeginPath()
+	ctx.Rect(32, 0, 16, 16)
+	ctx.SetFillColor(color.RGBA{R: 255, G: 255, B: 255, A: 255})
+	ctx.Fill()
+	ctx.Restore()
+
+	// Restore() brings back the normal blending.
+	ctx.BeginPath()
+	ctx.Rect(48, 0, 16, 16)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 8, 8, color.RGBA{R: 128, A: 255})
+	// The blend mode acts like normal blending over transparent pixels.
+	checkPixel(t, dst, 8, 56, red)
+	checkPixel(t, dst, 24, 8, color.RGBA{R: 128, G: 128, B: 255, A: 255})
+	checkPixel(t, dst, 40, 8, color.RGBA{R: 127, G: 127, B: 127, A: 255})
+	checkPixel(t, dst, 56, 8, blue)
+}
+
+func TestBlendChannel(t *testing.T) {
+	for _, c := range []struct {
+		mode     BlendMode
+		s, d     float32
+		expected float32
+	}{
+		{BlendNormal, 0.25, 0.5, 0.25},
+		{BlendOverlay, 0.5, 0.25, 0.25},
+		{BlendOverlay, 0.5, 0.75, 0.75},
+		{BlendDarken, 0.25, 0.5, 0.25},
+		{BlendLighten, 0.25, 0.5, 0.5},
+		{BlendColorDodge, 0.5, 0.25, 0.5},
+		{BlendColorDodge, 1.0, 0.25, 1.0},
+		{BlendColorDodge, 0.5, 0.0, 0.0},
+	} {
+		if actual := blendChannel(c.mode, c.s, c.d); absF(actual-c.expected) > 0.001 {
+			t.Errorf("blendChannel(%d, %f, %f) should be %f, but %f", c.mode, c.s, c.d, c.expected, actual)
+		}
+	}
+}
+
+func TestVectorContextBlendMode(t *testing.T) {
+	draw := func(ctx *Context) {
+		ctx.SetBlendMode(BlendColorDodge)
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 10, 10)
+		ctx.Fill()
+	}
+	if svg := renderSVG(t, draw); !strings.Contains(svg, `<g style="mix-blend-mode:color-dodge">`) {
+		t.Errorf("SVG should have mix-blend-mode:\n%s", svg)
+	}
+	if pdf := renderPDF(t, 1, draw); !strings.Contains(pdf, "<< /BM /ColorDodge >>") {
+		t.Errorf("PDF should have the blend mode:\n%s", pdf)
+	}
+}
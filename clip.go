@@ -0,0 +1,60 @@
+package nanovgo
+
+// Clip is a clip path set by Context.ClipPath(). The clip region is the intersection of the clip and its parents.
+// Clips are not modified after they are made, so render states share them, and renderers can use them as keys.
+type Clip struct {
+	// Parent is the clip that was set before, or nil.
+	Parent *Clip
+	// Paths have the fill vertexes of the clip path as triangle fans. They are in the canvas coordinates.
+	Paths []Path
+	// Bounds is the bounds of Paths.
+	Bounds [4]float32
+	// FillRule is the fill rule that was set when the clip was made.
+	FillRule FillRule
+	commands []float32
+}
+
+// chain returns the clip and its parents from the outermost one.
+func (clip *Clip) chain() []*Clip {
+	var clips []*Clip
+	for c := clip; c != nil; c = c.Parent {
+		clips = append(clips, c)
+	}
+	for i, j := 0, len(clips)-1; i < j; i, j = i+1, j-1 {
+		clips[i], clips[j] = clips[j], clips[i]
+	}
+	return clips
+}
+
+// ClipPath intersects the current clip region with the current path, and following drawing is clipped by it.
+// The path is filled by the same rule as Fill(), and it is transformed by the current transform.
+// The clip region is a part of the render state, so Restore() brings back the previous clip region.
+// It is also intersected with the scissor. Unlike the scissor, the edges of the clip region are not anti-aliased.
+func (c *Context) ClipPath() {
+	if c.recorder != nil {
+		defer c.record(OpClipPath)()
+	}
+	state := c.getState()
+	c.flattenPaths()
+	c.cache.expandFill(0.0, Miter, 2.4, c.fringeWidth)
+
+	clip := &Clip{
+		Parent:   state.clip,
+		Paths:    make([]Path, len(c.cache.paths)),
+		Bounds:   c.cache.bounds,
+		FillRule: state.fillRule,
+		commands: append([]float32(nil), c.commands...),
+	}
+	for i := range c.cache.paths {
+		clip.Paths[i].Fills = append([]Vertex(nil), c.cache.paths[i].Fills...)
+	}
+	state.clip = clip
+}
+
+// ResetClip resets and disables clipping by ClipPath().
+func (c *Context) ResetClip() {
+	if c.recorder != nil {
+		defer c.record(OpResetClip)()
+	}
+	c.getState().clip = nil
+}
@@ -0,0 +1,101 @@
+package nanovgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClipPath(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.Save()
+	ctx.BeginPath()
+	ctx.Circle(32, 32, 16)
+	ctx.ClipPath()
+
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.Restore()
+
+	checkPixel(t, dst, 32, 32, red)
+	checkPixel(t, dst, 20, 32, red)
+	checkPixel(t, dst, 19, 19, transparent)
+	checkPixel(t, dst, 2, 2, transparent)
+
+	// Restore() brings back the state without the clip.
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 8, 8)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 2, 2, blue)
+}
+
+func TestClipPathIntersection(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 40, 64)
+	ctx.ClipPath()
+	ctx.BeginPath()
+	ctx.Rect(20, 0, 44, 64)
+	ctx.ClipPath()
+	ctx.Scissor(0, 0, 64, 32)
+
+	// Concave fill uses the stencil buffer with the clip.
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.Rect(28, 8, 4, 4)
+	ctx.PathWinding(Hole)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+
+	ctx.BeginPath()
+	ctx.MoveTo(0, 20)
+	ctx.LineTo(64, 20)
+	ctx.SetStrokeWidth(4)
+	ctx.SetStrokeColor(blue)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 24, 4, red)
+	checkPixel(t, dst, 29, 9, transparent)
+	checkPixel(t, dst, 10, 4, transparent)
+	checkPixel(t, dst, 50, 4, transparent)
+	checkPixel(t, dst, 24, 40, transparent)
+	checkPixel(t, dst, 30, 20, blue)
+	checkPixel(t, dst, 10, 20, transparent)
+
+	ctx.BeginFrame(64, 64, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.ClipPath()
+	ctx.ResetClip()
+	if ctx.getState().clip != nil {
+		t.Error("ResetClip() should disable the clip")
+	}
+}
+
+func TestSVGContextClipPath(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 20, 20)
+		ctx.ClipPath()
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 15, 15)
+		ctx.ClipPath()
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.Fill()
+	})
+	for _, expected := range []string{
+		`<clipPath id="clip1"><path d="M10 10L10 30L30 30L30 10Z"/></clipPath>`,
+		`<clipPath id="clip2" clip-path="url(#clip1)"><path d="M0 0L0 15L15 15L15 0Z"/></clipPath>`,
+		`<g clip-path="url(#clip2)">`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("SVG should contain %s:\n%s", expected, svg)
+		}
+	}
+}
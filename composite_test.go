@@ -0,0 +1,71 @@
+package nanovgo
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestCompositeOperation(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 32, 64)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+
+	ctx.Save()
+	ctx.SetGlobalCompositeOperation(DestinationOut)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 16)
+	ctx.Fill()
+
+	ctx.SetGlobalCompositeOperation(Lighter)
+	ctx.BeginPath()
+	ctx.Rect(0, 16, 64, 16)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+
+	ctx.SetGlobalCompositeOperation(SourceIn)
+	ctx.BeginPath()
+	ctx.Rect(0, 32, 64, 16)
+	ctx.Fill()
+	ctx.Restore()
+
+	// Restore() brings back source-over.
+	ctx.BeginPath()
+	ctx.Rect(0, 48, 64, 16)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 8, 8, transparent)
+	checkPixel(t, dst, 24, 8, red)
+	checkPixel(t, dst, 8, 24, color.RGBA{R: 255, B: 255, A: 255})
+	checkPixel(t, dst, 40, 24, blue)
+	checkPixel(t, dst, 8, 40, blue)
+	checkPixel(t, dst, 40, 40, transparent)
+	checkPixel(t, dst, 40, 56, red)
+}
+
+func TestCompositeBlendFuncSeparate(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 16, 16, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 16)
+	ctx.SetFillColor(color.RGBA{R: 128, A: 128})
+	ctx.Fill()
+
+	// Replace the colors, and keep the alpha of the destination.
+	ctx.SetGlobalCompositeBlendFuncSeparate(One, Zero, Zero, One)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 8, 16)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+
+	// Invalid factors fall back to source-over.
+	ctx.SetGlobalCompositeBlendFunc(BlendFactor(0), One)
+	ctx.BeginPath()
+	ctx.Rect(8, 0, 8, 16)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 4, 8, color.RGBA{B: 255, A: 128})
+	checkPixel(t, dst, 12, 8, blue)
+}
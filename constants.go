@@ -74,3 +74,112 @@ const (
 	// Hole keeps internal hole
 	Hole Winding = 2
 )
+
+// FillRule is used with Context.SetFillRule to specify which parts of paths are filled
+type FillRule int
+
+const (
+	// NonZero fills the areas where the winding numbers are not zero (default value)
+	NonZero FillRule = iota
+	// EvenOdd fills the areas where the paths are crossed odd times from the outside
+	EvenOdd
+)
+
+// TextureType is used for Renderer.CreateTexture
+type TextureType int
+
+const (
+	// TextureAlpha is a texture that has only alpha channel. It is used for font images.
+	TextureAlpha TextureType = 1
+	// TextureRGBA is a texture that has RGBA channels.
+	TextureRGBA TextureType = 2
+)
+
+// ImageFlags is used when Context.CreateFramebuffer() to specify how the image is sampled
+type ImageFlags int
+
+const (
+	// ImageNearest samples the image by nearest filtering instead of linear filtering
+	ImageNearest ImageFlags = 1 << 0
+)
+
+// CompositeOperation is used with Context.SetGlobalCompositeOperation to specify how shapes are composited
+// with the destination. They are the Porter-Duff operators of HTML5 canvas.
+type CompositeOperation int
+
+const (
+	// SourceOver draws shapes over the destination (default value)
+	SourceOver CompositeOperation = iota
+	// SourceIn draws shapes only where the destination is opaque
+	SourceIn
+	// SourceOut draws shapes only where the destination is transparent
+	SourceOut
+	// Atop draws shapes only where the destination is opaque, over the destination
+	Atop
+	// DestinationOver draws shapes behind the destination
+	DestinationOver
+	// DestinationIn keeps the destination only where shapes are opaque
+	DestinationIn
+	// DestinationOut erases the destination where shapes are opaque
+	DestinationOut
+	// DestinationAtop keeps the destination only where shapes are opaque, over shapes
+	DestinationAtop
+	// Lighter adds the colors of shapes and the destination
+	Lighter
+	// Copy replaces the destination with shapes
+	Copy
+	// Xor keeps shapes and the destination only where the other is transparent
+	Xor
+)
+
+// BlendFactor is used with Context.SetGlobalCompositeBlendFunc to specify the factor of the blend equation.
+// Colors are alpha-premultiplied.
+type BlendFactor int
+
+const (
+	// Zero is (0, 0, 0, 0)
+	Zero BlendFactor = 1 << 0
+	// One is (1, 1, 1, 1)
+	One BlendFactor = 1 << 1
+	// SrcColor is the color of shapes
+	SrcColor BlendFactor = 1 << 2
+	// OneMinusSrcColor is one minus the color of shapes
+	OneMinusSrcColor BlendFactor = 1 << 3
+	// DstColor is the color of the destination
+	DstColor BlendFactor = 1 << 4
+	// OneMinusDstColor is one minus the color of the destination
+	OneMinusDstColor BlendFactor = 1 << 5
+	// SrcAlpha is the alpha of shapes
+	SrcAlpha BlendFactor = 1 << 6
+	// OneMinusSrcAlpha is one minus the alpha of shapes
+	OneMinusSrcAlpha BlendFactor = 1 << 7
+	// DstAlpha is the alpha of the destination
+	DstAlpha BlendFactor = 1 << 8
+	// OneMinusDstAlpha is one minus the alpha of the destination
+	OneMinusDstAlpha BlendFactor = 1 << 9
+	// SrcAlphaSaturate is min(alpha of shapes, one minus alpha of the destination) for colors, and one for alpha
+	SrcAlphaSaturate BlendFactor = 1 << 10
+)
+
+// BlendMode is used with Context.SetBlendMode to specify how the colors of shapes are mixed with the destination.
+// They are the separable blend modes of CSS and PDF.
+type BlendMode int
+
+const (
+	// BlendNormal uses the colors of shapes as they are (default value)
+	BlendNormal BlendMode = iota
+	// BlendMultiply multiplies the colors of shapes and the destination. The result is always darker.
+	BlendMultiply
+	// BlendScreen multiplies the complements of the colors. The result is always lighter.
+	BlendScreen
+	// BlendOverlay multiplies or screens the colors depending on the destination
+	BlendOverlay
+	// BlendDarken selects the darker of the colors
+	BlendDarken
+	// BlendLighten selects the lighter of the colors
+	BlendLighten
+	// BlendColorDodge brightens the destination to reflect the colors of shapes
+	BlendColorDodge
+	// BlendDifference subtracts the darker of the colors from the lighter
+	BlendDifference
+)
@@ -10,6 +10,9 @@ const (
 	nvgInitPathsSize    = 16
 	nvgInitVertsSize    = 256
 	nvgMaxStates        = 32
+	nvgMaxArcDivs       = 32
+
+	nvgGradientLarge float32 = 1e5
 )
 
 type nvgCommands int
@@ -31,13 +34,6 @@ const (
 	nvgPrINNERBEVEL nvgPointFlags = 0x08
 )
 
-type nvgTextureType int
-
-const (
-	nvgTextureALPHA nvgTextureType = 1
-	nvgTextureRGBA  nvgTextureType = 2
-)
-
 type nvgCodePointSize int
 
 const (
@@ -0,0 +1,152 @@
+package nanovgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineDash(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 48, AntiAlias)
+	ctx.SetStrokeColor(red)
+	ctx.SetStrokeWidth(4)
+	ctx.SetLineDash([]float32{8})
+	ctx.BeginPath()
+	ctx.MoveTo(0, 8)
+	ctx.LineTo(64, 8)
+	ctx.Stroke()
+
+	ctx.SetLineDashOffset(4)
+	ctx.BeginPath()
+	ctx.MoveTo(0, 24)
+	ctx.LineTo(64, 24)
+	ctx.Stroke()
+
+	// The path is flattened again without the dashes.
+	ctx.BeginPath()
+	ctx.Rect(8, 32, 48, 12)
+	ctx.Stroke()
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 4, 8, red)
+	checkPixel(t, dst, 12, 8, transparent)
+	checkPixel(t, dst, 20, 8, red)
+	checkPixel(t, dst, 8, 24, transparent)
+	checkPixel(t, dst, 16, 24, red)
+	checkPixel(t, dst, 32, 38, blue)
+}
+
+func TestDashPaths(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+	ctx.BeginPath()
+	ctx.MoveTo(0, 0)
+	ctx.LineTo(10, 0)
+	ctx.LineTo(10, 10)
+	ctx.LineTo(0, 10)
+	ctx.ClosePath()
+	ctx.flattenPaths()
+	// The flattened points are reversed for the winding, but the dashes start from the first point.
+	// The dash continues over the corner (10,10), and the last dash is joined to the first dash over the start point.
+	ctx.cache.dashPaths([]float32{15, 5}, 5, ctx.distTol)
+	expected := [][]float32{
+		{10, 5, 10, 10, 0, 10},
+		{0, 5, 0, 0, 10, 0},
+	}
+	if len(ctx.cache.paths) != len(expected) {
+		t.Fatalf("path should be split into %d dashes, but %d", len(expected), len(ctx.cache.paths))
+	}
+	for i, path := range ctx.cache.paths {
+		points := ctx.cache.points[path.first : path.first+path.count]
+		if path.closed || len(points)*2 != len(expected[i]) {
+			t.Errorf("dash %d should be open and have %d points, but %v", i, len(expected[i])/2, points)
+			continue
+		}
+		for j, point := range points {
+			if absF(point.x-expected[i][j*2]) > 0.001 || absF(point.y-expected[i][j*2+1]) > 0.001 {
+				t.Errorf("point %d of dash %d should be (%f, %f), but (%f, %f)", j, i, expected[i][j*2], expected[i][j*2+1], point.x, point.y)
+			}
+		}
+	}
+}
+
+func TestVectorContextLineDash(t *testing.T) {
+	draw := func(ctx *Context) {
+		ctx.Scale(2, 2)
+		ctx.SetLineDash([]float32{3, 1, 2})
+		ctx.SetLineDashOffset(1)
+		ctx.BeginPath()
+		ctx.MoveTo(0, 0)
+		ctx.LineTo(10, 0)
+		ctx.Stroke()
+	}
+	if svg := renderSVG(t, draw); !strings.Contains(svg, `stroke-dasharray="6 2 4 6 2 4" stroke-dashoffset="2"`) {
+		t.Errorf("SVG should have the dash pattern:\n%s", svg)
+	}
+	if pdf := renderPDF(t, 1, draw); !strings.Contains(pdf, "[6 2 4 6 2 4] 2 d") {
+		t.Errorf("PDF should have the dash pattern:\n%s", pdf)
+	}
+}
+
+func TestVectorContextLineDashStart(t *testing.T) {
+	// The stroked path keeps the order of the points, so the dashes start from the first point
+	// like TestDashPaths, even though the filled path is reversed for the winding.
+	draw := func(ctx *Context) {
+		ctx.SetLineDash([]float32{15, 5})
+		ctx.BeginPath()
+		ctx.MoveTo(0, 0)
+		ctx.LineTo(10, 0)
+		ctx.LineTo(10, 10)
+		ctx.LineTo(0, 10)
+		ctx.ClosePath()
+		ctx.Stroke()
+	}
+	if svg := renderSVG(t, draw); !strings.Contains(svg, `d="M0 0L10 0L10 10L0 10Z"`) {
+		t.Errorf("SVG should stroke the path from the first point:\n%s", svg)
+	}
+	if pdf := renderPDF(t, 1, draw); !strings.Contains(pdf, "0 0 m\n10 0 l\n10 10 l\n0 10 l\nh\n") {
+		t.Errorf("PDF should stroke the path from the first point:\n%s", pdf)
+	}
+}
+
+func TestVectorContextLineDashSubpaths(t *testing.T) {
+	// The second subpath continues the pattern after the closed square of the length 40.
+	draw := func(ctx *Context) {
+		ctx.SetLineDash([]float32{15, 5})
+		ctx.SetLineDashOffset(1)
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 10, 10)
+		ctx.MoveTo(0, 20)
+		ctx.LineTo(10, 20)
+		ctx.Stroke()
+	}
+	svg := renderSVG(t, draw)
+	if !strings.Contains(svg, `d="M0 0L0 10L10 10L10 0Z" fill="none"`) || !strings.Contains(svg, `stroke-dashoffset="1"`) {
+		t.Errorf("SVG should stroke the first subpath with the dash offset:\n%s", svg)
+	}
+	if !strings.Contains(svg, `d="M0 20L10 20" fill="none"`) || !strings.Contains(svg, `stroke-dashoffset="41"`) {
+		t.Errorf("SVG should continue the dash pattern on the second subpath:\n%s", svg)
+	}
+	pdf := renderPDF(t, 1, draw)
+	if !strings.Contains(pdf, "[15 5] 1 d\n0 0 m\n") || !strings.Contains(pdf, "[15 5] 41 d\n0 20 m\n10 20 l\nS\n") {
+		t.Errorf("PDF should continue the dash pattern on the second subpath:\n%s", pdf)
+	}
+}
+
+func TestRecorderLineDash(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+	recorder := &Recorder{}
+	ctx.SetRecorder(recorder)
+	ctx.SetLineDash([]float32{4, 2})
+	ctx.SetLineDash([]float32{-1})
+	ctx.SetLineDashOffset(3)
+
+	replayCtx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+	if _, err := recorder.DisplayList().Replay(replayCtx); err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	state := replayCtx.getState()
+	if len(state.lineDash) != 2 || state.lineDash[0] != 4 || state.lineDash[1] != 2 || state.dashOffset != 3 {
+		t.Errorf("line dash should be replayed, but %v %f", state.lineDash, state.dashOffset)
+	}
+}
@@ -0,0 +1,12 @@
+package nanovgo
+
+// SetFillRule sets the fill rule of Fill() and ClipPath(). The default is NonZero.
+// NonZero fills sub-paths by their windings that are set by PathWinding(), so the directions of the points are
+// ignored. EvenOdd fills the areas inside of odd number of sub-paths, so self-intersecting paths and nested sub-paths
+// have holes regardless of their windings. The fill rule is a part of the render state.
+func (c *Context) SetFillRule(rule FillRule) {
+	if c.recorder != nil {
+		defer c.record(OpSetFillRule, float32(rule))()
+	}
+	c.getState().fillRule = rule
+}
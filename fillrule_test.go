@@ -0,0 +1,83 @@
+package nanovgo
+
+import (
+	"strings"
+	"testing"
+)
+
+// starPath makes the self-intersecting star that has the pentagon in the center.
+func starPath(ctx *Context, cx, cy, r float32) {
+	ctx.BeginPath()
+	for i := 0; i < 5; i++ {
+		a := float32(i*4)*PI/5 - PI/2
+		if i == 0 {
+			ctx.MoveTo(cx+cosF(a)*r, cy+sinF(a)*r)
+		} else {
+			ctx.LineTo(cx+cosF(a)*r, cy+sinF(a)*r)
+		}
+	}
+	ctx.ClosePath()
+}
+
+func TestFillRule(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.SetFillColor(red)
+	starPath(ctx, 16, 16, 14)
+	ctx.Fill()
+
+	ctx.SetFillRule(EvenOdd)
+	starPath(ctx, 48, 16, 14)
+	ctx.Fill()
+
+	// Both sub-paths are solid, but the inner one is a hole by the even-odd rule.
+	ctx.BeginPath()
+	ctx.Rect(0, 32, 64, 32)
+	ctx.Rect(16, 40, 32, 16)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 16, 16, red)
+	checkPixel(t, dst, 16, 7, red)
+	checkPixel(t, dst, 48, 16, transparent)
+	checkPixel(t, dst, 48, 7, red)
+	checkPixel(t, dst, 8, 48, red)
+	checkPixel(t, dst, 32, 48, transparent)
+}
+
+func TestClipPathFillRule(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.SetFillRule(EvenOdd)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.Rect(16, 16, 32, 32)
+	ctx.ClipPath()
+	// The clip keeps its fill rule.
+	ctx.SetFillRule(NonZero)
+
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 8, 8, red)
+	checkPixel(t, dst, 32, 32, transparent)
+}
+
+func TestVectorContextFillRule(t *testing.T) {
+	draw := func(ctx *Context) {
+		ctx.SetFillRule(EvenOdd)
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 10, 10)
+		ctx.ClipPath()
+		ctx.Fill()
+	}
+	svg := renderSVG(t, draw)
+	if !strings.Contains(svg, `clip-rule="evenodd"`) || !strings.Contains(svg, `fill-rule="evenodd"`) {
+		t.Errorf("SVG should have the even-odd rule:\n%s", svg)
+	}
+	pdf := renderPDF(t, 1, draw)
+	if !strings.Contains(pdf, "W* n\n") || !strings.Contains(pdf, "f*\n") {
+		t.Errorf("PDF should have the even-odd rule:\n%s", pdf)
+	}
+}
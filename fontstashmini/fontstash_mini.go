@@ -190,6 +190,10 @@ func (stash *FontStash) GetFontName() string {
 	return stash.fonts[stash.state.font].name
 }
 
+func (stash *FontStash) GetFontData() []byte {
+	return stash.fonts[stash.state.font].data
+}
+
 func (stash *FontStash) VerticalMetrics() (float32, float32, float32) {
 	state := stash.state
 	if len(stash.fonts) < state.font+1 {
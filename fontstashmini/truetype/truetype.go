@@ -51,6 +51,11 @@ func (font *FontInfo) ScaleForPixelHeight(height float64) float64 {
 	return height / fheight
 }
 
+func (font *FontInfo) ScaleForMappingEmToPixels(pixels float64) float64 {
+	unitsPerEm := float64(u16(font.data, font.head+18))
+	return pixels / unitsPerEm
+}
+
 func (font *FontInfo) GetGlyphBitmapBox(glyph int, scaleX, scaleY float64) (int, int, int, int) {
 	return font.GetGlyphBitmapBoxSubpixel(glyph, scaleX, scaleY, 0, 0)
 }
@@ -0,0 +1,76 @@
+package nanovgo
+
+import (
+	"errors"
+)
+
+// Framebuffer is an offscreen drawing target created by Context.CreateFramebuffer().
+// The drawn result can be used as an image via Framebuffer.Image().
+type Framebuffer struct {
+	id            int
+	image         int
+	width, height int
+}
+
+// Image returns the image handle of the framebuffer. It can be used with ImagePattern() like other images.
+func (fb *Framebuffer) Image() int {
+	return fb.image
+}
+
+// Size returns the size of the framebuffer in pixels.
+func (fb *Framebuffer) Size() (int, int) {
+	return fb.width, fb.height
+}
+
+// CreateFramebuffer creates an offscreen framebuffer that has the color image and the stencil buffer.
+// The framebuffer is cleared to transparent.
+func (c *Context) CreateFramebuffer(w, h int, flags ImageFlags) (*Framebuffer, error) {
+	renderer, ok := c.renderer.(FramebufferRenderer)
+	if !ok {
+		return nil, errors.New("renderer doesn't support framebuffers")
+	}
+	id, image, err := renderer.CreateFramebuffer(w, h, flags)
+	if err != nil {
+		return nil, err
+	}
+	return &Framebuffer{
+		id:     id,
+		image:  image,
+		width:  w,
+		height: h,
+	}, nil
+}
+
+// BindFramebuffer makes the framebuffer the drawing target. Pass nil to draw into the default target again.
+// Draw calls are issued at Context.EndFrame(), so call it outside of Context.BeginFrame() & Context.EndFrame() like:
+//
+//	ctx.BindFramebuffer(fb)
+//	ctx.BeginFrame(width, height, 1.0)
+//	// draw
+//	ctx.EndFrame()
+//	ctx.BindFramebuffer(nil)
+//
+// On GL backends, the viewport is set to the framebuffer and restored when the default target is bound again.
+func (c *Context) BindFramebuffer(fb *Framebuffer) {
+	renderer, ok := c.renderer.(FramebufferRenderer)
+	if !ok {
+		return
+	}
+	if fb == nil {
+		renderer.BindFramebuffer(0)
+	} else {
+		renderer.BindFramebuffer(fb.id)
+	}
+	c.framebuffer = fb
+}
+
+// DeleteFramebuffer deletes the framebuffer and its image.
+func (c *Context) DeleteFramebuffer(fb *Framebuffer) {
+	renderer, ok := c.renderer.(FramebufferRenderer)
+	if !ok || fb == nil {
+		return
+	}
+	renderer.DeleteFramebuffer(fb.id)
+	fb.id = 0
+	fb.image = 0
+}
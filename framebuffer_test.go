@@ -0,0 +1,62 @@
+package nanovgo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFramebufferImage(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.EndFrame()
+
+	fb, err := ctx.CreateFramebuffer(32, 32, 0)
+	if err != nil {
+		t.Fatalf("CreateFramebuffer() failed: %v", err)
+	}
+	if w, h, _ := ctx.ImageSize(fb.Image()); w != 32 || h != 32 {
+		t.Errorf("framebuffer image should be 32x32, but %dx%d", w, h)
+	}
+
+	// Concave fill needs the stencil buffer of the framebuffer.
+	ctx.BindFramebuffer(fb)
+	ctx.BeginFrame(32, 32, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 32, 32)
+	ctx.Rect(8, 8, 16, 16)
+	ctx.PathWinding(Hole)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.EndFrame()
+	ctx.BindFramebuffer(nil)
+
+	checkPixel(t, dst, 4, 4, transparent)
+
+	ctx.BeginFrame(64, 64, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(16, 16, 32, 32)
+	ctx.SetFillPaint(ImagePattern(16, 16, 32, 32, 0, fb.Image(), 1.0))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 20, 20, red)
+	checkPixel(t, dst, 32, 32, transparent)
+	checkPixel(t, dst, 44, 20, red)
+	checkPixel(t, dst, 8, 8, transparent)
+
+	image := fb.Image()
+	ctx.DeleteFramebuffer(fb)
+	if _, _, err := ctx.ImageSize(image); err == nil {
+		t.Error("DeleteFramebuffer() should delete the image")
+	}
+}
+
+func TestFramebufferUnsupported(t *testing.T) {
+	var buf bytes.Buffer
+	ctx, err := NewSVGContext(&buf)
+	if err != nil {
+		t.Fatalf("NewSVGContext() failed: %v", err)
+	}
+	if _, err := ctx.CreateFramebuffer(16, 16, ImageNearest); err == nil {
+		t.Error("CreateFramebuffer() should fail on the renderer without framebuffers")
+	}
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/shibukawa/nanovgo/fontstashmini"
-
 	"github.com/goxjs/gl"
 )
 
@@ -14,36 +12,28 @@ const (
 	glnvgLocVIEWSIZE = iota
 	glnvgLocTEX
 	glnvgLocFRAG
+	glnvgLocMASK
+	glnvgLocDST
+	glnvgLocDSTRECT
 	glnvgMaxLOCS
 )
 
-// NewContext makes new NanoVGo context that is entry point of this API
-func NewContext(flags CreateFlags) (*Context, error) {
-	context := &Context{
-		gl: &glContext{
-			isEdgeAntiAlias: (flags & AntiAlias) != 0,
-			flags:           flags,
-		},
-		states:     make([]nvgState, 0, nvgMaxStates),
-		fontImages: make([]int, nvgMaxFontImages),
-		commands:   make([]float32, 0, nvgInitCommandsSize),
-		cache: nvgPathCache{
-			points:   make([]nvgPoint, 0, nvgInitPointsSize),
-			paths:    make([]nvgPath, 0, nvgInitPathsSize),
-			vertexes: make([]nvgVertex, 0, nvgInitVertsSize),
-		},
-	}
-	context.Save()
-	context.getState().reset()
-	context.setDevicePixelRatio(1.0)
-	context.gl.renderCreate()
-
-	context.fs = fontstashmini.New(nvgInitFontImageSize, nvgInitFontImageSize)
+const (
+	// glnvgRampSize is the width of the ramp textures of the gradient stops.
+	glnvgRampSize = 256
+	// glnvgRampLifetime is the number of frames that unused ramp textures are kept.
+	glnvgRampLifetime = 60
+)
 
-	context.fontImages[0] = context.gl.renderCreateTexture(nvgTextureALPHA, nvgInitFontImageSize, nvgInitFontImageSize, nil)
-	context.fontImageIdx = 0
+// glnvgClipBit is the stencil bit that marks the inside of the clip region. Lower bits are used to fill paths.
+const glnvgClipBit = 0x80
 
-	return context, nil
+// NewContext makes new NanoVGo context that is entry point of this API
+func NewContext(flags CreateFlags) (*Context, error) {
+	return NewContextWithRenderer(&glContext{
+		isEdgeAntiAlias: (flags & AntiAlias) != 0,
+		flags:           flags,
+	})
 }
 
 type glShader struct {
@@ -109,6 +99,9 @@ func (s *glShader) getUniforms() {
 	s.locations[glnvgLocVIEWSIZE] = gl.GetUniformLocation(s.program, "viewSize")
 	s.locations[glnvgLocTEX] = gl.GetUniformLocation(s.program, "tex")
 	s.locations[glnvgLocFRAG] = gl.GetUniformLocation(s.program, "frag")
+	s.locations[glnvgLocMASK] = gl.GetUniformLocation(s.program, "maskTex")
+	s.locations[glnvgLocDST] = gl.GetUniformLocation(s.program, "dstTex")
+	s.locations[glnvgLocDSTRECT] = gl.GetUniformLocation(s.program, "dstRect")
 }
 
 type glContext struct {
@@ -123,6 +116,28 @@ type glContext struct {
 	vertexes     []float32
 	uniforms     []glFragUniforms
 
+	framebuffers  []*glFramebuffer
+	framebufferID int
+	framebuffer   *glFramebuffer
+	viewport      [4]int32
+
+	clip           *Clip
+	clipPaths      map[*Clip][2]int
+	clipQuadOffset int
+	stencilClip    *Clip
+
+	mask      int
+	composite CompositeOperationState
+	blendMode BlendMode
+	fillRule  FillRule
+
+	ramps map[string]*glRamp
+	frame int
+
+	dstTexture     gl.Texture
+	dstTextureSize [2]int
+	dstViewport    [4]int32
+
 	stencilMask     uint32
 	stencilFunc     gl.Enum
 	stencilFuncRef  int
@@ -168,7 +183,7 @@ func (c *glContext) setStencilFunc(fun gl.Enum, ref int, mask uint32) {
 	if c.stencilFunc != fun || c.stencilFuncRef != ref || c.stencilFuncMask != mask {
 		c.stencilFunc = fun
 		c.stencilFuncRef = ref
-		c.stencilMask = mask
+		c.stencilFuncMask = mask
 		gl.StencilFunc(fun, ref, mask)
 	}
 }
@@ -218,48 +233,75 @@ func (c *glContext) allocTexture() *glTexture {
 	return tex
 }
 
-func (c *glContext) convertPaint(frag *glFragUniforms, paint *Paint, scissor *nvgScissor, width, fringe, strokeThr float32) error {
-	frag.setInnerColor(paint.color)
-	frag.setOuterColor(paint.color) // TODO: remove
+func (c *glContext) convertPaint(frag *glFragUniforms, paint *Paint, scissor *Scissor, width, fringe, strokeThr float32) error {
+	frag.setInnerColor(paint.InnerColor)
+	frag.setOuterColor(paint.OuterColor)
 
-	if scissor.extent[0] < -0.5 || scissor.extent[1] < -0.5 {
+	if scissor.Extent[0] < -0.5 || scissor.Extent[1] < -0.5 {
 		frag.clearScissorMat()
 		frag.setScissorExt(1.0, 1.0)
 		frag.setScissorScale(1.0, 1.0)
 	} else {
-		xform := &scissor.xform
+		xform := &scissor.Xform
 		frag.setScissorMat(xform.Inverse().ToMat3x4())
-		frag.setScissorExt(scissor.extent[0], scissor.extent[1])
+		frag.setScissorExt(scissor.Extent[0], scissor.Extent[1])
 		scaleX := sqrtF(xform[0]*xform[0]+xform[2]*xform[2]) / fringe
 		scaleY := sqrtF(xform[1]*xform[1]+xform[3]*xform[3]) / fringe
 		frag.setScissorScale(scaleX, scaleY)
 	}
-	frag.setExtent([2]float32{0, 0}) // TODO: remove
+	frag.setExtent(paint.Extent)
 	frag.setStrokeMult((width*0.5 + fringe*0.5) / fringe)
 	frag.setStrokeThr(strokeThr)
 
-	if paint.image != 0 {
-		tex := c.findTexture(paint.image)
+	if paint.Image != 0 {
+		tex := c.findTexture(paint.Image)
 		if tex == nil {
 			return errors.New("invalid texture in GLParams.convertPaint")
 		}
-		frag.setPaintMat(IdentityMatrix().ToMat3x4())
+		if tex.flipY {
+			// Framebuffer images are drawn upside down.
+			flip := TransformMatrix{1, 0, 0, -1, 0, paint.Extent[1]}
+			frag.setPaintMat(paint.Xform.Inverse().Multiply(flip).ToMat3x4())
+		} else {
+			frag.setPaintMat(paint.Xform.Inverse().ToMat3x4())
+		}
 		frag.setType(nsvgShaderFILLIMG)
 
-		if tex.texType == nvgTextureRGBA {
-			if false { // TODO: remove
-				frag.setTexType(0)
-			} else {
-				frag.setTexType(1)
-			}
+		if tex.texType == TextureRGBA {
+			// Pixels come from image.RGBA, which is already alpha-premultiplied.
+			frag.setTexType(0)
 		} else {
 			frag.setTexType(2)
 		}
 	} else {
-		frag.setType(nsvgShaderFILLGRAD)
-		frag.setRadius(0)
-		frag.setFeather(1)
-		frag.setPaintMat(IdentityMatrix().Inverse().ToMat3x4())
+		if paint.Conic {
+			frag.setType(nsvgShaderFILLCONIC)
+		} else if len(paint.Stops) > 0 {
+			frag.setType(nsvgShaderFILLRAMP)
+		} else {
+			frag.setType(nsvgShaderFILLGRAD)
+		}
+		frag.setRadius(paint.Radius)
+		frag.setFeather(paint.Feather)
+		frag.setPaintMat(paint.Xform.Inverse().ToMat3x4())
+	}
+
+	frag.setBlendMode(float32(c.blendMode))
+
+	if c.mask != 0 {
+		tex := c.findTexture(c.mask)
+		if tex == nil {
+			return errors.New("invalid mask texture in GLParams.convertPaint")
+		}
+		var flipY float32
+		if tex.flipY {
+			flipY = 1
+		}
+		if tex.texType == TextureRGBA {
+			frag.setMask(1/c.view[0], 1/c.view[1], flipY, 1)
+		} else {
+			frag.setMask(1/c.view[0], 1/c.view[1], flipY, 2)
+		}
 	}
 
 	return nil
@@ -282,16 +324,21 @@ func (c *glContext) fill(call *glCall) {
 
 	// Draw shapes
 	gl.Enable(gl.STENCIL_TEST)
-	c.setStencilMask(0xff)
-	c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+	if call.clip != nil {
+		// Count the winding by the lower bits only inside of the clip region.
+		c.setStencilMask(0x7f)
+		c.setStencilFunc(gl.EQUAL, glnvgClipBit, glnvgClipBit)
+	} else {
+		c.setStencilMask(0xff)
+		c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+	}
 	gl.ColorMask(false, false, false, false)
 
 	// set bindpoint for solid loc
 	c.setUniforms(call.uniformOffset, 0)
 	checkError(c, "fill simple")
 
-	gl.StencilOpSeparate(gl.FRONT, gl.KEEP, gl.KEEP, gl.INCR_WRAP)
-	gl.StencilOpSeparate(gl.BACK, gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	c.setStencilFillRule(call.fillRule)
 
 	gl.Disable(gl.CULL_FACE)
 	for i := call.pathOffset; i < pathSentinel; i++ {
@@ -305,7 +352,7 @@ func (c *glContext) fill(call *glCall) {
 	c.setUniforms(call.uniformOffset+1, call.image)
 
 	if c.flags&AntiAlias != 0 {
-		c.setStencilFunc(gl.EQUAL, 0x00, 0xff)
+		c.setStencilFunc(gl.EQUAL, c.stencilBase(call), 0xff)
 		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
 		// Draw fringes
 		for i := call.pathOffset; i < pathSentinel; i++ {
@@ -315,7 +362,11 @@ func (c *glContext) fill(call *glCall) {
 	}
 
 	// Draw fill
-	c.setStencilFunc(gl.NOTEQUAL, 0x00, 0xff)
+	if call.clip != nil {
+		c.setStencilFunc(gl.LESS, glnvgClipBit, 0xff)
+	} else {
+		c.setStencilFunc(gl.NOTEQUAL, 0x00, 0xff)
+	}
 	gl.StencilOp(gl.ZERO, gl.ZERO, gl.ZERO)
 	gl.DrawArrays(gl.TRIANGLES, call.triangleOffset, call.triangleCount)
 
@@ -327,6 +378,8 @@ func (c *glContext) convexFill(call *glCall) {
 
 	c.setUniforms(call.uniformOffset, call.image)
 	checkError(c, "convex fill")
+	c.beginClipTest(call)
+	defer c.endClipTest(call)
 
 	for i := range paths {
 		path := &paths[i]
@@ -349,7 +402,10 @@ func (c *glContext) stroke(call *glCall) {
 		c.setStencilMask(0xff)
 
 		// Fill the stroke base without overlap
-		c.setStencilFunc(gl.EQUAL, 0x00, 0xff)
+		if call.clip != nil {
+			c.setStencilMask(0x7f)
+		}
+		c.setStencilFunc(gl.EQUAL, c.stencilBase(call), 0xff)
 		gl.StencilOp(gl.KEEP, gl.KEEP, gl.INCR)
 		c.setUniforms(call.uniformOffset+1, call.image)
 		checkError(c, "stroke fill 0")
@@ -360,7 +416,7 @@ func (c *glContext) stroke(call *glCall) {
 
 		// Draw anti-aliased pixels.
 		c.setUniforms(call.uniformOffset, call.image)
-		c.setStencilFunc(gl.EQUAL, 0x00, 0xff)
+		c.setStencilFunc(gl.EQUAL, c.stencilBase(call), 0xff)
 		gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
 		for i := range paths {
 			path := &paths[i]
@@ -381,6 +437,8 @@ func (c *glContext) stroke(call *glCall) {
 	} else {
 		c.setUniforms(call.uniformOffset, call.image)
 		checkError(c, "stroke fill")
+		c.beginClipTest(call)
+		defer c.endClipTest(call)
 		for i := range paths {
 			path := &paths[i]
 			gl.DrawArrays(gl.TRIANGLE_STRIP, path.strokeOffset, path.strokeCount)
@@ -391,25 +449,29 @@ func (c *glContext) stroke(call *glCall) {
 func (c *glContext) triangles(call *glCall) {
 	c.setUniforms(call.uniformOffset, call.image)
 	checkError(c, "triangles fill")
+	c.beginClipTest(call)
+	defer c.endClipTest(call)
 	gl.DrawArrays(gl.TRIANGLES, call.triangleOffset, call.triangleCount)
 }
 
 func (c *glContext) triangleStrip(call *glCall) {
 	c.setUniforms(call.uniformOffset, call.image)
 	checkError(c, "triangle strip fill")
+	c.beginClipTest(call)
+	defer c.endClipTest(call)
 	gl.DrawArrays(gl.TRIANGLE_STRIP, call.triangleOffset, call.triangleCount)
 }
 
-func (c *glContext) edgeAntiAlias() bool {
+func (c *glContext) EdgeAntiAlias() bool {
 	return c.isEdgeAntiAlias
 }
 
-func (c *glContext) renderCreate() error {
+func (c *glContext) Create() error {
 	//align := 4
 
 	checkError(c, "init")
 
-	if c.edgeAntiAlias() {
+	if c.EdgeAntiAlias() {
 		err := c.shader.createShader("shader", shaderHeader, "#define EDGE_AA 1", fillVertexShader, fillFragmentShader)
 		if err != nil {
 			return err
@@ -431,18 +493,19 @@ func (c *glContext) renderCreate() error {
 	return nil
 }
 
-func (c *glContext) renderCreateTexture(texType nvgTextureType, w, h int, data []byte) int {
+func (c *glContext) CreateTexture(texType TextureType, w, h int, data []byte) int {
 
 	tex := c.allocTexture()
 	tex.tex = gl.CreateTexture()
 	tex.width = w
 	tex.height = h
 	tex.texType = texType
+	tex.flipY = false
 
 	c.bindTexture(&tex.tex)
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
 
-	if texType == nvgTextureRGBA {
+	if texType == TextureRGBA {
 		data = prepareTextureBuffer(data, w, h, 4)
 		gl.TexImage2D(gl.TEXTURE_2D, 0, w, h, gl.RGBA, gl.UNSIGNED_BYTE, data)
 	} else {
@@ -464,7 +527,7 @@ func (c *glContext) renderCreateTexture(texType nvgTextureType, w, h int, data [
 	return tex.id
 }
 
-func (c *glContext) renderDeleteTexture(id int) error {
+func (c *glContext) DeleteTexture(id int) error {
 	tex := c.findTexture(id)
 	if tex.tex.Valid() {
 		gl.DeleteTexture(tex.tex)
@@ -475,7 +538,7 @@ func (c *glContext) renderDeleteTexture(id int) error {
 	return errors.New("invalid texture in GLParams.deleteTexture")
 }
 
-func (c *glContext) renderUpdateTexture(image, x, y, w, h int, data []byte) error {
+func (c *glContext) UpdateTexture(image, x, y, w, h int, data []byte) error {
 	tex := c.findTexture(image)
 	if tex == nil {
 		return errors.New("invalid texture in GLParams.updateTexture")
@@ -483,7 +546,7 @@ func (c *glContext) renderUpdateTexture(image, x, y, w, h int, data []byte) erro
 	c.bindTexture(&tex.tex)
 	gl.PixelStorei(gl.UNPACK_ALIGNMENT, 1)
 
-	if tex.texType == nvgTextureRGBA {
+	if tex.texType == TextureRGBA {
 		data = data[y*tex.width*4:]
 	} else {
 		data = data[y*tex.width:]
@@ -491,7 +554,7 @@ func (c *glContext) renderUpdateTexture(image, x, y, w, h int, data []byte) erro
 	x = 0
 	w = tex.width
 
-	if tex.texType == nvgTextureRGBA {
+	if tex.texType == TextureRGBA {
 		gl.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, w, h, gl.RGBA, gl.UNSIGNED_BYTE, data)
 	} else {
 		gl.TexSubImage2D(gl.TEXTURE_2D, 0, x, y, w, h, gl.LUMINANCE, gl.UNSIGNED_BYTE, data)
@@ -503,7 +566,7 @@ func (c *glContext) renderUpdateTexture(image, x, y, w, h int, data []byte) erro
 	return nil
 }
 
-func (c *glContext) renderGetTextureSize(image int) (int, int, error) {
+func (c *glContext) GetTextureSize(image int) (int, int, error) {
 	tex := c.findTexture(image)
 	if tex == nil {
 		return -1, -1, errors.New("invalid texture in GLParams.getTextureSize")
@@ -511,23 +574,23 @@ func (c *glContext) renderGetTextureSize(image int) (int, int, error) {
 	return tex.width, tex.height, nil
 }
 
-func (c *glContext) renderViewport(width, height int) {
+func (c *glContext) Viewport(width, height int) {
 	c.view[0] = float32(width)
 	c.view[1] = float32(height)
 }
 
-func (c *glContext) renderCancel() {
+func (c *glContext) Cancel() {
 	c.vertexes = c.vertexes[:0]
 	c.paths = c.paths[:0]
 	c.calls = c.calls[:0]
 	c.uniforms = c.uniforms[:0]
+	c.clipPaths = nil
 }
 
-func (c *glContext) renderFlush() {
+func (c *glContext) Flush() {
 	if len(c.calls) > 0 {
 		gl.UseProgram(c.shader.program)
 
-		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
 		gl.Enable(gl.CULL_FACE)
 		gl.CullFace(gl.BACK)
 		gl.FrontFace(gl.CCW)
@@ -556,10 +619,28 @@ func (c *glContext) renderFlush() {
 
 		// Set view and texture just once per frame.
 		gl.Uniform1i(c.shader.locations[glnvgLocTEX], 0)
+		gl.Uniform1i(c.shader.locations[glnvgLocMASK], 1)
 		gl.Uniform2fv(c.shader.locations[glnvgLocVIEWSIZE], c.view[:])
 
+		blending := c.prepareDstTexture()
+
+		c.stencilClip = nil
+		mask := 0
+		var composite CompositeOperationState
 		for i := range c.calls {
 			call := &c.calls[i]
+			c.updateStencilClip(call.clip)
+			if i == 0 || call.composite != composite {
+				blendCompositeOperation(call.composite)
+				composite = call.composite
+			}
+			if call.blendMode != BlendNormal {
+				c.copyDstTexture()
+			}
+			if call.mask != mask {
+				c.bindMask(call.mask)
+				mask = call.mask
+			}
 			switch call.callType {
 			case glnvgFILL:
 				c.fill(call)
@@ -573,6 +654,15 @@ func (c *glContext) renderFlush() {
 				c.triangleStrip(call)
 			}
 		}
+		c.updateStencilClip(nil)
+		if mask != 0 {
+			c.bindMask(0)
+		}
+		if blending {
+			gl.ActiveTexture(gl.TEXTURE2)
+			c.bindTexture(nil)
+			gl.ActiveTexture(gl.TEXTURE0)
+		}
 		gl.DisableVertexAttribArray(c.shader.vertexAttrib)
 		gl.DisableVertexAttribArray(c.shader.tcoordAttrib)
 		gl.Disable(gl.CULL_FACE)
@@ -584,18 +674,25 @@ func (c *glContext) renderFlush() {
 	c.paths = c.paths[:0]
 	c.calls = c.calls[:0]
 	c.uniforms = c.uniforms[:0]
+	c.clipPaths = nil
+	c.evictRamps()
 }
 
-func (c *glContext) renderFill(paint *Paint, scissor *nvgScissor, fringe float32, bounds [4]float32, paths []nvgPath) {
+func (c *glContext) Fill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []Path) {
 	var glPaths []glPath
 	c.calls = append(c.calls, glCall{
 		pathCount: len(paths),
-		image:     paint.image,
+		image:     c.paintImage(paint),
+		clip:      c.clip,
+		mask:      c.mask,
+		composite: c.composite,
+		blendMode: c.blendMode,
+		fillRule:  c.fillRule,
 	})
 	call := &c.calls[len(c.calls)-1]
 	glPaths, call.pathOffset = c.allocPath(call.pathCount)
 
-	if len(paths) == 0 && paths[0].convex {
+	if len(paths) == 1 && paths[0].Convex {
 		call.callType = glnvgCONVEXFILL
 	} else {
 		call.callType = glnvgFILL
@@ -607,16 +704,16 @@ func (c *glContext) renderFill(paint *Paint, scissor *nvgScissor, fringe float32
 		glPath := &glPaths[i]
 		path := &paths[i]
 
-		fillCount := len(path.fills)
+		fillCount := len(path.Fills)
 		if fillCount > 0 {
 			glPath.fillOffset = vertexOffset / 4
 			glPath.fillCount = fillCount
 			for j := 0; j < fillCount; j++ {
-				vertex := &path.fills[j]
-				c.vertexes[vertexOffset] = vertex.x
-				c.vertexes[vertexOffset+1] = vertex.y
-				c.vertexes[vertexOffset+2] = vertex.u
-				c.vertexes[vertexOffset+3] = vertex.v
+				vertex := &path.Fills[j]
+				c.vertexes[vertexOffset] = vertex.X
+				c.vertexes[vertexOffset+1] = vertex.Y
+				c.vertexes[vertexOffset+2] = vertex.U
+				c.vertexes[vertexOffset+3] = vertex.V
 				vertexOffset += 4
 			}
 		} else {
@@ -624,16 +721,16 @@ func (c *glContext) renderFill(paint *Paint, scissor *nvgScissor, fringe float32
 			glPath.fillCount = 0
 		}
 
-		strokeCount := len(path.strokes)
+		strokeCount := len(path.Strokes)
 		if strokeCount > 0 {
 			glPath.strokeOffset = vertexOffset / 4
 			glPath.strokeCount = strokeCount
 			for j := 0; j < strokeCount; j++ {
-				vertex := &path.strokes[j]
-				c.vertexes[vertexOffset] = vertex.x
-				c.vertexes[vertexOffset+1] = vertex.y
-				c.vertexes[vertexOffset+2] = vertex.u
-				c.vertexes[vertexOffset+3] = vertex.v
+				vertex := &path.Strokes[j]
+				c.vertexes[vertexOffset] = vertex.X
+				c.vertexes[vertexOffset+1] = vertex.Y
+				c.vertexes[vertexOffset+2] = vertex.U
+				c.vertexes[vertexOffset+3] = vertex.V
 				vertexOffset += 4
 			}
 		} else {
@@ -702,14 +799,14 @@ func (c *glContext) renderFill(paint *Paint, scissor *nvgScissor, fringe float32
 	c.convertPaint(paintFrag, paint, scissor, fringe, fringe, -1.0)
 }
 
-func (c *glContext) renderStroke(paint *Paint, scissor *nvgScissor, fringe float32, strokeWidth float32, paths []nvgPath) {
+func (c *glContext) Stroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []Path) {
 	var glPaths []glPath
-	c.calls = append(c.calls, glCall{})
+	c.calls = append(c.calls, glCall{clip: c.clip, mask: c.mask, composite: c.composite, blendMode: c.blendMode})
 	call := &c.calls[len(c.calls)-1]
 	call.callType = glnvgSTROKE
 	glPaths, call.pathOffset = c.allocPath(len(paths))
 	call.pathCount = len(paths)
-	call.image = paint.image
+	call.image = c.paintImage(paint)
 
 	// Allocate vertices for all the paths
 	vertexOffset := c.allocVertexMemory(maxVertexCount(paths))
@@ -718,16 +815,16 @@ func (c *glContext) renderStroke(paint *Paint, scissor *nvgScissor, fringe float
 		glPath := &glPaths[i]
 		path := &paths[i]
 
-		strokeCount := len(path.strokes)
+		strokeCount := len(path.Strokes)
 		if strokeCount > 0 {
 			glPath.strokeOffset = vertexOffset / 4
 			glPath.strokeCount = strokeCount
 			for j := 0; j < strokeCount; j++ {
-				vertex := &path.strokes[j]
-				c.vertexes[vertexOffset] = vertex.x
-				c.vertexes[vertexOffset+1] = vertex.y
-				c.vertexes[vertexOffset+2] = vertex.u
-				c.vertexes[vertexOffset+3] = vertex.v
+				vertex := &path.Strokes[j]
+				c.vertexes[vertexOffset] = vertex.X
+				c.vertexes[vertexOffset+1] = vertex.Y
+				c.vertexes[vertexOffset+2] = vertex.U
+				c.vertexes[vertexOffset+3] = vertex.V
 				vertexOffset += 4
 			}
 		} else {
@@ -755,25 +852,29 @@ func (c *glContext) renderStroke(paint *Paint, scissor *nvgScissor, fringe float
 	}
 }
 
-func (c *glContext) renderTriangles(paint *Paint, scissor *nvgScissor, vertexes []nvgVertex) {
+func (c *glContext) Triangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
 	vertexCount := len(vertexes)
 	vertexOffset := c.allocVertexMemory(vertexCount)
 	callIndex := len(c.calls)
 
 	c.calls = append(c.calls, glCall{
 		callType:       glnvgTRIANGLES,
-		image:          paint.image,
+		image:          c.paintImage(paint),
 		triangleOffset: vertexOffset / 4,
 		triangleCount:  vertexCount,
+		clip:           c.clip,
+		mask:           c.mask,
+		composite:      c.composite,
+		blendMode:      c.blendMode,
 	})
 	call := &c.calls[callIndex]
 
 	for i := 0; i < vertexCount; i++ {
 		vertex := &vertexes[i]
-		c.vertexes[vertexOffset] = vertex.x
-		c.vertexes[vertexOffset+1] = vertex.y
-		c.vertexes[vertexOffset+2] = vertex.u
-		c.vertexes[vertexOffset+3] = vertex.v
+		c.vertexes[vertexOffset] = vertex.X
+		c.vertexes[vertexOffset+1] = vertex.Y
+		c.vertexes[vertexOffset+2] = vertex.U
+		c.vertexes[vertexOffset+3] = vertex.V
 		vertexOffset += 4
 	}
 
@@ -786,25 +887,29 @@ func (c *glContext) renderTriangles(paint *Paint, scissor *nvgScissor, vertexes
 	f0.setType(nsvgShaderIMG)
 }
 
-func (c *glContext) renderTriangleStrip(paint *Paint, scissor *nvgScissor, vertexes []nvgVertex) {
+func (c *glContext) TriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
 	vertexCount := len(vertexes)
 	vertexOffset := c.allocVertexMemory(vertexCount)
 	callIndex := len(c.calls)
 
 	c.calls = append(c.calls, glCall{
 		callType:       glnvgTRIANGLESTRIP,
-		image:          paint.image,
+		image:          c.paintImage(paint),
 		triangleOffset: vertexOffset / 4,
 		triangleCount:  vertexCount,
+		clip:           c.clip,
+		mask:           c.mask,
+		composite:      c.composite,
+		blendMode:      c.blendMode,
 	})
 	call := &c.calls[callIndex]
 
 	for i := 0; i < vertexCount; i++ {
 		vertex := &vertexes[i]
-		c.vertexes[vertexOffset] = vertex.x
-		c.vertexes[vertexOffset+1] = vertex.y
-		c.vertexes[vertexOffset+2] = vertex.u
-		c.vertexes[vertexOffset+3] = vertex.v
+		c.vertexes[vertexOffset] = vertex.X
+		c.vertexes[vertexOffset+1] = vertex.Y
+		c.vertexes[vertexOffset+2] = vertex.U
+		c.vertexes[vertexOffset+3] = vertex.V
 		vertexOffset += 4
 	}
 
@@ -817,16 +922,419 @@ func (c *glContext) renderTriangleStrip(paint *Paint, scissor *nvgScissor, verte
 	f0.setType(nsvgShaderIMG)
 }
 
-func (c *glContext) renderDelete() {
+func (c *glContext) SetClip(clip *Clip) {
+	c.clip = clip
+	if clip == nil {
+		return
+	}
+	if c.clipPaths == nil {
+		c.clipPaths = make(map[*Clip][2]int)
+		c.clipQuadOffset = c.allocQuad(0, 0, c.view[0], c.view[1])
+	}
+	// Upload the paths of the clip and its parents that are not used in this frame yet.
+	for ; clip != nil; clip = clip.Parent {
+		if _, ok := c.clipPaths[clip]; ok {
+			break
+		}
+		glPaths, pathOffset := c.allocPath(len(clip.Paths))
+		vertexOffset := c.allocVertexMemory(maxVertexCount(clip.Paths))
+		for i := range clip.Paths {
+			fills := clip.Paths[i].Fills
+			glPaths[i].fillOffset = vertexOffset / 4
+			glPaths[i].fillCount = len(fills)
+			for j := range fills {
+				vertex := &fills[j]
+				c.vertexes[vertexOffset] = vertex.X
+				c.vertexes[vertexOffset+1] = vertex.Y
+				c.vertexes[vertexOffset+2] = vertex.U
+				c.vertexes[vertexOffset+3] = vertex.V
+				vertexOffset += 4
+			}
+		}
+		c.clipPaths[clip] = [2]int{pathOffset, len(clip.Paths)}
+	}
+}
+
+func (c *glContext) SetMask(image int) {
+	c.mask = image
+}
+
+// bindMask binds the mask texture to the second texture unit.
+func (c *glContext) bindMask(image int) {
+	gl.ActiveTexture(gl.TEXTURE1)
+	if tex := c.findTexture(image); image != 0 && tex != nil {
+		c.bindTexture(&tex.tex)
+	} else {
+		c.bindTexture(nil)
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+	c.checkError("mask tex")
+}
+
+// paintImage returns the texture that the paint samples. Gradients that have stops and conic gradients sample
+// the ramp texture.
+func (c *glContext) paintImage(paint *Paint) int {
+	if paint.Image == 0 && (len(paint.Stops) > 0 || paint.Conic) {
+		return c.rampTexture(paint.gradientStops())
+	}
+	return paint.Image
+}
+
+// rampTexture returns the texture that the colors of the gradient stops are baked into.
+// Textures are cached until they are not used for glnvgRampLifetime frames.
+func (c *glContext) rampTexture(stops GradientStops) int {
+	key := gradientStopsKey(stops)
+	if ramp, ok := c.ramps[key]; ok {
+		ramp.frame = c.frame
+		return ramp.image
+	}
+	image := c.CreateTexture(TextureRGBA, glnvgRampSize, 1, bakeGradientRamp(stops, glnvgRampSize))
+	if c.ramps == nil {
+		c.ramps = make(map[string]*glRamp)
+	}
+	c.ramps[key] = &glRamp{image: image, frame: c.frame}
+	return image
+}
+
+// evictRamps deletes the ramp textures that are not used recently, and advances the frame counter.
+func (c *glContext) evictRamps() {
+	for key, ramp := range c.ramps {
+		if c.frame-ramp.frame > glnvgRampLifetime {
+			c.deleteTexture(ramp.image)
+			delete(c.ramps, key)
+		}
+	}
+	c.frame++
+}
+
+func (c *glContext) SetCompositeOperation(op CompositeOperationState) {
+	c.composite = op
+}
+
+func (c *glContext) SetBlendMode(mode BlendMode) {
+	c.blendMode = mode
+}
+
+func (c *glContext) SetFillRule(rule FillRule) {
+	c.fillRule = rule
+}
+
+// prepareDstTexture allocates the texture that the destination is copied into if the calls use blend modes.
+// It returns false if no call uses blend modes.
+func (c *glContext) prepareDstTexture() bool {
+	blending := false
+	for i := range c.calls {
+		if c.calls[i].blendMode != BlendNormal {
+			blending = true
+			break
+		}
+	}
+	if !blending {
+		return false
+	}
+	gl.GetIntegerv(c.dstViewport[:], gl.VIEWPORT)
+	w, h := int(c.dstViewport[2]), int(c.dstViewport[3])
+
+	gl.ActiveTexture(gl.TEXTURE2)
+	if !c.dstTexture.Valid() {
+		c.dstTexture = gl.CreateTexture()
+	}
+	c.bindTexture(&c.dstTexture)
+	if c.dstTextureSize != [2]int{w, h} {
+		gl.TexImage2D(gl.TEXTURE_2D, 0, w, h, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		c.dstTextureSize = [2]int{w, h}
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+
+	dstRect := [4]float32{float32(c.dstViewport[0]), float32(c.dstViewport[1]), 1.0 / float32(w), 1.0 / float32(h)}
+	gl.Uniform1i(c.shader.locations[glnvgLocDST], 2)
+	gl.Uniform4fv(c.shader.locations[glnvgLocDSTRECT], dstRect[:])
+	c.checkError("dst tex")
+	return true
+}
+
+// copyDstTexture copies the current destination into the texture that blend modes sample.
+func (c *glContext) copyDstTexture() {
+	gl.ActiveTexture(gl.TEXTURE2)
+	gl.CopyTexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, int(c.dstViewport[0]), int(c.dstViewport[1]), int(c.dstViewport[2]), int(c.dstViewport[3]))
+	gl.ActiveTexture(gl.TEXTURE0)
+}
+
+// blendCompositeOperation sets the blend function. Invalid factors fall back to source-over.
+func blendCompositeOperation(op CompositeOperationState) {
+	srcRGB, ok1 := glBlendFactor(op.SrcRGB)
+	dstRGB, ok2 := glBlendFactor(op.DstRGB)
+	srcAlpha, ok3 := glBlendFactor(op.SrcAlpha)
+	dstAlpha, ok4 := glBlendFactor(op.DstAlpha)
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		gl.BlendFunc(gl.ONE, gl.ONE_MINUS_SRC_ALPHA)
+		return
+	}
+	gl.BlendFuncSeparate(srcRGB, dstRGB, srcAlpha, dstAlpha)
+}
+
+func glBlendFactor(factor BlendFactor) (gl.Enum, bool) {
+	switch factor {
+	case Zero:
+		return gl.ZERO, true
+	case One:
+		return gl.ONE, true
+	case SrcColor:
+		return gl.SRC_COLOR, true
+	case OneMinusSrcColor:
+		return gl.ONE_MINUS_SRC_COLOR, true
+	case DstColor:
+		return gl.DST_COLOR, true
+	case OneMinusDstColor:
+		return gl.ONE_MINUS_DST_COLOR, true
+	case SrcAlpha:
+		return gl.SRC_ALPHA, true
+	case OneMinusSrcAlpha:
+		return gl.ONE_MINUS_SRC_ALPHA, true
+	case DstAlpha:
+		return gl.DST_ALPHA, true
+	case OneMinusDstAlpha:
+		return gl.ONE_MINUS_DST_ALPHA, true
+	case SrcAlphaSaturate:
+		return gl.SRC_ALPHA_SATURATE, true
+	}
+	return 0, false
+}
+
+// allocQuad stores two triangles that cover the rectangle, and returns the offset of the first vertex.
+func (c *glContext) allocQuad(x0, y0, x1, y1 float32) int {
+	vertexOffset := c.allocVertexMemory(6)
+	quad := [6][2]float32{{x0, y1}, {x1, y1}, {x1, y0}, {x0, y1}, {x1, y0}, {x0, y0}}
+	for i, p := range quad {
+		c.vertexes[vertexOffset+i*4] = p[0]
+		c.vertexes[vertexOffset+i*4+1] = p[1]
+		c.vertexes[vertexOffset+i*4+2] = 0.5
+		c.vertexes[vertexOffset+i*4+3] = 1.0
+	}
+	return vertexOffset / 4
+}
+
+// updateStencilClip writes the clip region into the clip bit of the stencil buffer.
+func (c *glContext) updateStencilClip(clip *Clip) {
+	if clip == c.stencilClip {
+		return
+	}
+	c.stencilClip = clip
+
+	// Clear the clip bit
+	c.setStencilMask(glnvgClipBit)
+	gl.ClearStencil(0)
+	gl.Clear(gl.STENCIL_BUFFER_BIT)
+	if clip == nil {
+		return
+	}
+
+	gl.Enable(gl.STENCIL_TEST)
+	gl.ColorMask(false, false, false, false)
+	gl.Disable(gl.CULL_FACE)
+	for i, clip := range clip.chain() {
+		// Count the winding of the clip path by the lower bits.
+		c.setStencilMask(0x7f)
+		if i == 0 {
+			c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+		} else {
+			c.setStencilFunc(gl.EQUAL, glnvgClipBit, glnvgClipBit)
+		}
+		c.setStencilFillRule(clip.FillRule)
+		paths := c.clipPaths[clip]
+		for j := paths[0]; j < paths[0]+paths[1]; j++ {
+			path := &c.paths[j]
+			gl.DrawArrays(gl.TRIANGLE_FAN, path.fillOffset, path.fillCount)
+		}
+
+		// Set the clip bit of the pixels inside of the first path, and clear the bit of the pixels outside of others.
+		c.setStencilMask(glnvgClipBit)
+		if i == 0 {
+			c.setStencilFunc(gl.NOTEQUAL, 0x00, 0x7f)
+			gl.StencilOp(gl.KEEP, gl.KEEP, gl.INVERT)
+		} else {
+			c.setStencilFunc(gl.EQUAL, glnvgClipBit, 0xff)
+			gl.StencilOp(gl.KEEP, gl.KEEP, gl.ZERO)
+		}
+		gl.DrawArrays(gl.TRIANGLES, c.clipQuadOffset, 6)
+
+		// Clear the lower bits
+		c.setStencilMask(0x7f)
+		c.setStencilFunc(gl.ALWAYS, 0x00, 0xff)
+		gl.StencilOp(gl.ZERO, gl.ZERO, gl.ZERO)
+		gl.DrawArrays(gl.TRIANGLES, c.clipQuadOffset, 6)
+	}
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+	gl.Enable(gl.CULL_FACE)
+	gl.ColorMask(true, true, true, true)
+	gl.Disable(gl.STENCIL_TEST)
+	c.checkError("clip")
+}
+
+// setStencilFillRule sets the stencil operations that count the paths by the fill rule.
+// The non-zero rule counts the winding, and the even-odd rule flips the bits of the parity.
+func (c *glContext) setStencilFillRule(rule FillRule) {
+	if rule == EvenOdd {
+		gl.StencilOp(gl.KEEP, gl.KEEP, gl.INVERT)
+	} else {
+		gl.StencilOpSeparate(gl.FRONT, gl.KEEP, gl.KEEP, gl.INCR_WRAP)
+		gl.StencilOpSeparate(gl.BACK, gl.KEEP, gl.KEEP, gl.DECR_WRAP)
+	}
+}
+
+// stencilBase returns the stencil value of the pixels that are not filled yet.
+func (c *glContext) stencilBase(call *glCall) int {
+	if call.clip != nil {
+		return glnvgClipBit
+	}
+	return 0x00
+}
+
+// beginClipTest enables the stencil test that passes only inside of the clip region.
+func (c *glContext) beginClipTest(call *glCall) {
+	if call.clip == nil {
+		return
+	}
+	gl.Enable(gl.STENCIL_TEST)
+	c.setStencilFunc(gl.EQUAL, glnvgClipBit, glnvgClipBit)
+	gl.StencilOp(gl.KEEP, gl.KEEP, gl.KEEP)
+}
+
+func (c *glContext) endClipTest(call *glCall) {
+	if call.clip != nil {
+		gl.Disable(gl.STENCIL_TEST)
+	}
+}
+
+func (c *glContext) findFramebuffer(id int) *glFramebuffer {
+	for _, fb := range c.framebuffers {
+		if fb.id == id {
+			return fb
+		}
+	}
+	return nil
+}
+
+func (c *glContext) CreateFramebuffer(w, h int, flags ImageFlags) (int, int, error) {
+	image := c.CreateTexture(TextureRGBA, w, h, nil)
+	tex := c.findTexture(image)
+	tex.flipY = true
+	if flags&ImageNearest != 0 {
+		c.bindTexture(&tex.tex)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		c.bindTexture(nil)
+	}
+
+	var fb *glFramebuffer
+	for _, framebuffer := range c.framebuffers {
+		if framebuffer.id == 0 {
+			fb = framebuffer
+			break
+		}
+	}
+	if fb == nil {
+		fb = &glFramebuffer{}
+		c.framebuffers = append(c.framebuffers, fb)
+	}
+	c.framebufferID++
+	fb.id = c.framebufferID
+	fb.image = image
+	fb.fbo = gl.CreateFramebuffer()
+	fb.rbo = gl.CreateRenderbuffer()
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	gl.BindRenderbuffer(gl.RENDERBUFFER, fb.rbo)
+	gl.RenderbufferStorage(gl.RENDERBUFFER, gl.STENCIL_INDEX8, w, h)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, tex.tex, 0)
+	gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.STENCIL_ATTACHMENT, gl.RENDERBUFFER, fb.rbo)
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	if status == gl.FRAMEBUFFER_COMPLETE {
+		gl.ClearColor(0, 0, 0, 0)
+		gl.ClearStencil(0)
+		gl.Clear(gl.COLOR_BUFFER_BIT | gl.STENCIL_BUFFER_BIT)
+	}
+	gl.BindRenderbuffer(gl.RENDERBUFFER, gl.Renderbuffer{})
+	if c.framebuffer != nil {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, c.framebuffer.fbo)
+	} else {
+		gl.BindFramebuffer(gl.FRAMEBUFFER, gl.Framebuffer{})
+	}
+	c.checkError("create framebuffer")
+
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		c.DeleteFramebuffer(fb.id)
+		return 0, 0, fmt.Errorf("framebuffer is not complete: %08x", status)
+	}
+	return fb.id, image, nil
+}
+
+func (c *glContext) BindFramebuffer(id int) error {
+	if id == 0 {
+		if c.framebuffer != nil {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, gl.Framebuffer{})
+			gl.Viewport(int(c.viewport[0]), int(c.viewport[1]), int(c.viewport[2]), int(c.viewport[3]))
+			c.framebuffer = nil
+		}
+		return nil
+	}
+	fb := c.findFramebuffer(id)
+	if fb == nil {
+		return errors.New("invalid framebuffer in GLParams.BindFramebuffer")
+	}
+	if c.framebuffer == nil {
+		// Keep the viewport of the default framebuffer to restore it.
+		gl.GetIntegerv(c.viewport[:], gl.VIEWPORT)
+	}
+	w, h, _ := c.GetTextureSize(fb.image)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, fb.fbo)
+	gl.Viewport(0, 0, w, h)
+	c.framebuffer = fb
+	return nil
+}
+
+func (c *glContext) DeleteFramebuffer(id int) error {
+	fb := c.findFramebuffer(id)
+	if fb == nil {
+		return errors.New("invalid framebuffer in GLParams.DeleteFramebuffer")
+	}
+	if c.framebuffer == fb {
+		c.BindFramebuffer(0)
+	}
+	gl.DeleteFramebuffer(fb.fbo)
+	gl.DeleteRenderbuffer(fb.rbo)
+	c.DeleteTexture(fb.image)
+	fb.id = 0
+	fb.fbo = gl.Framebuffer{}
+	fb.rbo = gl.Renderbuffer{}
+	fb.image = 0
+	return nil
+}
+
+func (c *glContext) Delete() {
 	c.shader.deleteShader()
 	if c.vertexBuffer.Valid() {
 		gl.DeleteBuffer(c.vertexBuffer)
 	}
+	for _, fb := range c.framebuffers {
+		if fb.id != 0 {
+			gl.DeleteFramebuffer(fb.fbo)
+			gl.DeleteRenderbuffer(fb.rbo)
+		}
+	}
 	for _, texture := range c.textures {
 		if texture.tex.Valid() {
 			gl.DeleteTexture(texture.tex)
 		}
 	}
+	if c.dstTexture.Valid() {
+		gl.DeleteTexture(c.dstTexture)
+	}
 }
 
 func dumpShaderError(shader gl.Shader, name, typeName string) error {
@@ -853,12 +1361,12 @@ func checkError(p *glContext, str string) {
 	}
 }
 
-func maxVertexCount(paths []nvgPath) int {
+func maxVertexCount(paths []Path) int {
 	count := 0
 	for i := range paths {
 		path := &paths[i]
-		count += len(path.fills)
-		count += len(path.strokes)
+		count += len(path.Fills)
+		count += len(path.Strokes)
 	}
 	return count
 }
@@ -907,12 +1415,19 @@ var fillFragmentShader = `
                float strokeThr;
                int texType;
                int type;
+               vec2 maskScale;
+               float maskFlip;
+               int maskType;
+               int blendMode;
        };
 #else
        // NANOVG_GL3 && !USE_UNIFORMBUF
        uniform vec4 frag[UNIFORMARRAY_SIZE];
 #endif
        uniform sampler2D tex;
+       uniform sampler2D maskTex;
+       uniform sampler2D dstTex;
+       uniform vec4 dstRect;
        in vec2 ftcoord;
        in vec2 fpos;
        out vec4 outColor;
@@ -920,6 +1435,9 @@ var fillFragmentShader = `
        // !NANOVG_GL3
        uniform vec4 frag[UNIFORMARRAY_SIZE];
        uniform sampler2D tex;
+       uniform sampler2D maskTex;
+       uniform sampler2D dstTex;
+       uniform vec4 dstRect;
        varying vec2 ftcoord;
        varying vec2 fpos;
 #endif
@@ -937,6 +1455,10 @@ var fillFragmentShader = `
        #define strokeThr frag[10].y
        #define texType int(frag[10].z)
        #define type int(frag[10].w)
+       #define maskScale frag[11].xy
+       #define maskFlip frag[11].z
+       #define maskType int(frag[11].w)
+       #define blendMode int(frag[12].x)
 #endif
 
 float sdroundrect(vec2 pt, vec2 ext, float rad) {
@@ -951,6 +1473,41 @@ float scissorMask(vec2 p) {
        sc = vec2(0.5,0.5) - sc * scissorScale;
        return clamp(sc.x,0.0,1.0) * clamp(sc.y,0.0,1.0);
 }
+// Alpha mask stretched over the view
+float maskAlpha(vec2 p) {
+       vec2 pt = p * maskScale;
+       if (maskFlip > 0.5) pt.y = 1.0 - pt.y;
+#ifdef NANOVG_GL3
+       vec4 color = texture(maskTex, pt);
+#else
+       vec4 color = texture2D(maskTex, pt);
+#endif
+       return maskType == 1 ? color.w : color.x;
+}
+// Blend modes - mix unpremultiplied colors
+vec3 blendColor(vec3 s, vec3 d) {
+       if (blendMode == 1) return s * d;
+       if (blendMode == 2) return s + d - s * d;
+       if (blendMode == 3) return mix(2.0*s*d, 1.0 - 2.0*(1.0-s)*(1.0-d), step(0.5, d));
+       if (blendMode == 4) return min(s, d);
+       if (blendMode == 5) return max(s, d);
+       if (blendMode == 6) return mix(min(vec3(1.0), d / max(vec3(1.0) - s, vec3(0.00001))), vec3(0.0), step(d, vec3(0.0)));
+       if (blendMode == 7) return abs(s - d);
+       return s;
+}
+
+// Blend with the copy of the destination. The result is composited by source-over blending.
+vec4 blendDst(vec4 src) {
+       vec2 pt = (gl_FragCoord.xy - dstRect.xy) * dstRect.zw;
+#ifdef NANOVG_GL3
+       vec4 dst = texture(dstTex, pt);
+#else
+       vec4 dst = texture2D(dstTex, pt);
+#endif
+       vec3 s = src.w > 0.0 ? src.xyz / src.w : vec3(0.0);
+       vec3 d = dst.w > 0.0 ? dst.xyz / dst.w : vec3(0.0);
+       return vec4(src.xyz * (1.0 - dst.w) + src.w * dst.w * blendColor(s, d), src.w);
+}
 #ifdef EDGE_AA
 // Stroke - from [0..1] to clipped pyramid, where the slope is 1px.
 float strokeMask() {
@@ -989,6 +1546,32 @@ void main(void) {
                // Combine alpha
                color *= strokeAlpha * scissor;
                result = color;
+       } else if (type == 4) {         // Gradient ramp
+               // Calculate the offset like type 0, and read the color from the centers of the 256 texels
+               vec2 pt = (paintMat * vec3(fpos,1.0)).xy;
+               float d = clamp((sdroundrect(pt, extent, radius) + feather*0.5) / feather, 0.0, 1.0);
+               vec2 rampPt = vec2(d * (255.0/256.0) + 0.5/256.0, 0.5);
+#ifdef NANOVG_GL3
+               vec4 color = texture(tex, rampPt);
+#else
+               vec4 color = texture2D(tex, rampPt);
+#endif
+               // Combine alpha
+               color *= strokeAlpha * scissor;
+               result = color;
+       } else if (type == 5) {         // Conic gradient
+               // The offset is the angle around the origin of the paint
+               vec2 pt = (paintMat * vec3(fpos,1.0)).xy;
+               float d = fract(atan(pt.y, pt.x) / 6.28318530718 + 1.0);
+               vec2 rampPt = vec2(d * (255.0/256.0) + 0.5/256.0, 0.5);
+#ifdef NANOVG_GL3
+               vec4 color = texture(tex, rampPt);
+#else
+               vec4 color = texture2D(tex, rampPt);
+#endif
+               // Combine alpha
+               color *= strokeAlpha * scissor;
+               result = color;
        } else if (type == 2) {         // Stencil fill
                result = vec4(1,1,1,1);
        } else if (type == 3) {         // Textured tris
@@ -1002,6 +1585,8 @@ void main(void) {
                color *= scissor;
                result = color * innerCol;
        }
+       if (maskType != 0) result *= maskAlpha(fpos);
+       if (blendMode != 0 && type != 2) result = blendDst(result);
 #ifdef EDGE_AA
        if (strokeAlpha < strokeThr) discard;
 #endif
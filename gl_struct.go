@@ -11,6 +11,8 @@ const (
 	nsvgShaderFILLIMG
 	nsvgShaderSIMPLE
 	nsvgShaderIMG
+	nsvgShaderFILLRAMP
+	nsvgShaderFILLCONIC
 )
 
 type glnvgCallType int
@@ -32,6 +34,11 @@ type glCall struct {
 	triangleOffset int
 	triangleCount  int
 	uniformOffset  int
+	clip           *Clip
+	mask           int
+	composite      CompositeOperationState
+	blendMode      BlendMode
+	fillRule       FillRule
 }
 
 type glPath struct {
@@ -41,10 +48,10 @@ type glPath struct {
 	strokeCount  int
 }
 
-type glFragUniforms [44]float32
+type glFragUniforms [52]float32
 
 func (u *glFragUniforms) reset() {
-	for i := 0; i < 44; i++ {
+	for i := 0; i < 52; i++ {
 		u[i] = 0
 	}
 }
@@ -115,9 +122,34 @@ func (u *glFragUniforms) setType(typeCode float32) {
 	u[43] = typeCode
 }
 
+func (u *glFragUniforms) setMask(scaleX, scaleY, flipY, maskType float32) {
+	u[44] = scaleX
+	u[45] = scaleY
+	u[46] = flipY
+	u[47] = maskType
+}
+
+func (u *glFragUniforms) setBlendMode(mode float32) {
+	u[48] = mode
+}
+
 type glTexture struct {
 	id            int
 	tex           gl.Texture
 	width, height int
-	texType       nvgTextureType
+	texType       TextureType
+	flipY         bool
+}
+
+type glFramebuffer struct {
+	id    int
+	fbo   gl.Framebuffer
+	rbo   gl.Renderbuffer
+	image int
+}
+
+// glRamp is the texture of gradient stops. frame is the last frame that the texture is used.
+type glRamp struct {
+	image int
+	frame int
 }
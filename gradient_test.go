@@ -0,0 +1,144 @@
+package nanovgo
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+var testStops = GradientStops{
+	{Offset: 0.75, Color: blue},
+	{Offset: 0.0, Color: red},
+	{Offset: 0.25, Color: red},
+}
+
+func TestLinearGradientStops(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 16, AntiAlias)
+	paint := LinearGradientStops(0, 0, 64, 0, testStops)
+	if paint.Stops[0].Offset != 0.0 || paint.Stops[2].Offset != 0.75 {
+		t.Errorf("stops should be sorted by the offsets, but %v", paint.Stops)
+	}
+	if !sameColor(paint.InnerColor, red) || !sameColor(paint.OuterColor, blue) {
+		t.Error("the inner and outer colors should be the first and last stops")
+	}
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 16)
+	ctx.SetFillPaint(paint)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 8, 8, red)
+	checkPixel(t, dst, 32, 8, color.RGBA{R: 124, B: 131, A: 255})
+	checkPixel(t, dst, 56, 8, blue)
+}
+
+func TestBakeGradientRamp(t *testing.T) {
+	ramp := bakeGradientRamp(LinearGradientStops(0, 0, 1, 0, testStops).Stops, 5)
+	expected := []byte{
+		255, 0, 0, 255,
+		255, 0, 0, 255,
+		127, 0, 127, 255,
+		0, 0, 255, 255,
+		0, 0, 255, 255,
+	}
+	for i := range expected {
+		if ramp[i] != expected[i] {
+			t.Fatalf("ramp should be %v, but %v", expected, ramp)
+		}
+	}
+	if gradientStopsKey(testStops) == gradientStopsKey(testStops[1:]) {
+		t.Error("different stops should have different keys")
+	}
+}
+
+func TestVectorContextGradientStops(t *testing.T) {
+	draw := func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.SetFillPaint(RadialGradientStops(50, 50, 0, 20, testStops))
+		ctx.Fill()
+	}
+	svg := renderSVG(t, draw)
+	if count := strings.Count(svg, "<stop "); count != 3 {
+		t.Errorf("SVG should have 3 stops, but %d:\n%s", count, svg)
+	}
+	pdf := renderPDF(t, 1, draw)
+	if !strings.Contains(pdf, "/FunctionType 3 /Domain [0 1]") || !strings.Contains(pdf, "/Bounds [0.25 0.75]") {
+		t.Errorf("PDF should have the stitching function:\n%s", pdf)
+	}
+}
+
+func TestRecorderGradientStops(t *testing.T) {
+	paint := LinearGradientStops(0, 0, 10, 0, testStops)
+	replayed := argsToPaint(paintToArgs(&paint))
+	if len(replayed.Stops) != 3 || replayed.Stops[1].Offset != 0.25 || !sameColor(replayed.Stops[2].Color, blue) {
+		t.Errorf("stops should be recorded, but %v", replayed.Stops)
+	}
+}
+
+func TestBoxGradientStops(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.SetFillPaint(BoxGradientStops(16, 16, 32, 32, 4, 16, testStops))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 32, 32, red)
+	checkPixel(t, dst, 2, 32, blue)
+	checkPixel(t, dst, 2, 2, blue)
+
+	draw := func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.SetFillPaint(BoxGradientStops(25, 25, 50, 50, 4, 16, testStops))
+		ctx.Fill()
+	}
+	if svg := renderSVG(t, draw); !strings.Contains(svg, "<pattern ") {
+		t.Errorf("SVG should have the pattern:\n%s", svg)
+	}
+	paint := BoxGradientStops(25, 25, 50, 50, 4, 16, testStops)
+	if replayed := argsToPaint(paintToArgs(&paint)); len(replayed.Stops) != 3 || replayed.Radius != 4 {
+		t.Error("the box gradient should be recorded")
+	}
+}
+
+func TestConicGradient(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	stops := GradientStops{
+		{Offset: 0.0, Color: red},
+		{Offset: 0.5, Color: blue},
+		{Offset: 1.0, Color: red},
+	}
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.SetFillPaint(ConicGradient(32, 32, 0, stops))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 60, 32, red)
+	checkPixel(t, dst, 32, 60, color.RGBA{R: 127, B: 127, A: 255})
+	checkPixel(t, dst, 4, 32, blue)
+	if offset := conicOffset(0, -1); absF(offset-0.75) > 0.001 {
+		t.Errorf("the offset above the center should be 0.75, but %f", offset)
+	}
+}
+
+func TestVectorContextConicGradient(t *testing.T) {
+	draw := func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.SetFillPaint(ConicGradient(50, 50, 0, testStops))
+		ctx.Fill()
+	}
+	if svg := renderSVG(t, draw); !strings.Contains(svg, "<pattern ") {
+		t.Errorf("SVG should have the pattern:\n%s", svg)
+	}
+	if pdf := renderPDF(t, 1, draw); !strings.Contains(pdf, "exch atan 360 div") {
+		t.Errorf("PDF should have the conic function:\n%s", pdf)
+	}
+	paint := ConicGradient(50, 50, 0, testStops)
+	if replayed := argsToPaint(paintToArgs(&paint)); !replayed.Conic || len(replayed.Stops) != 3 {
+		t.Error("the conic gradient should be recorded")
+	}
+}
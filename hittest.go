@@ -0,0 +1,159 @@
+package nanovgo
+
+// IsPointInPath returns true if the point (x,y) in the current local coordinates is inside of the current path.
+// The sub-paths are counted by their windings and the fill rule like Fill(), and open sub-paths are closed by
+// straight lines. The scissor and the clip region are not taken into account.
+func (c *Context) IsPointInPath(x, y float32) bool {
+	state := c.getState()
+	px, py := state.xform.TransformPoint(x, y)
+	c.flattenPaths()
+
+	winding, crossings := 0, 0
+	for i := range c.cache.paths {
+		path := &c.cache.paths[i]
+		points := c.cache.points[path.first : path.first+path.count]
+		for j := range points {
+			p0 := &points[j]
+			p1 := &points[(j+1)%len(points)]
+			if crossing := edgeCrossing(px, py, p0.x, p0.y, p1.x, p1.y); crossing != 0 {
+				winding += crossing
+				crossings++
+			}
+		}
+	}
+	if state.fillRule == EvenOdd {
+		return crossings%2 == 1
+	}
+	return winding != 0
+}
+
+// IsPointInStroke returns true if the point (x,y) in the current local coordinates is on the stroke of the current
+// path. The stroke width, line cap, line join, miter limit and line dash of the current state are taken into
+// account like Stroke(). The scissor and the clip region are not taken into account.
+func (c *Context) IsPointInStroke(x, y float32) bool {
+	state := c.getState()
+	scale := state.xform.getAverageScale()
+	w := clampF(state.strokeWidth*scale, 0.0, 200.0) * 0.5
+	px, py := state.xform.TransformPoint(x, y)
+	c.flattenPaths()
+	if len(state.lineDash) > 0 {
+		c.cache.dashPaths(scaleDash(state.lineDash, scale), state.dashOffset*scale, c.distTol)
+		// The dashes replace the flattened paths like Stroke().
+		defer c.cache.clearPathCache()
+	}
+
+	for i := range c.cache.paths {
+		path := &c.cache.paths[i]
+		points := c.cache.points[path.first : path.first+path.count]
+		if pointInStroke(points, path.closed, px, py, w, state.lineCap, state.lineJoin, state.miterLimit) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointInStroke returns true if (x,y) is within the half width w of the flattened path.
+func pointInStroke(points []nvgPoint, closed bool, x, y, w float32, lineCap, lineJoin LineCap, miterLimit float32) bool {
+	n := len(points)
+	if n < 2 {
+		return false
+	}
+	segments := n - 1
+	if closed {
+		segments = n
+	}
+
+	// Segments. Square caps extend the first and the last segments of open paths.
+	for i := 0; i < segments; i++ {
+		p := &points[i]
+		if p.len <= 0.0 {
+			continue
+		}
+		var ext0, ext1 float32
+		if !closed && lineCap == Square {
+			if i == 0 {
+				ext0 = w
+			}
+			if i == segments-1 {
+				ext1 = w
+			}
+		}
+		dx, dy := x-p.x, y-p.y
+		t := dx*p.dx + dy*p.dy
+		d := dx*p.dy - dy*p.dx
+		if t >= -ext0 && t <= p.len+ext1 && absF(d) <= w {
+			return true
+		}
+	}
+
+	// Round caps
+	if !closed && lineCap == Round {
+		for _, p := range []*nvgPoint{&points[0], &points[n-1]} {
+			dx, dy := x-p.x, y-p.y
+			if dx*dx+dy*dy <= w*w {
+				return true
+			}
+		}
+	}
+
+	// Joins
+	for i := range points {
+		if !closed && (i == 0 || i == n-1) {
+			continue
+		}
+		if pointInJoin(&points[(i+n-1)%n], &points[i], x, y, w, lineJoin, miterLimit) {
+			return true
+		}
+	}
+	return false
+}
+
+// pointInJoin returns true if (x,y) is in the outer part of the join at p1 that connects the segment from p0.
+func pointInJoin(p0, p1 *nvgPoint, x, y, w float32, lineJoin LineCap, miterLimit float32) bool {
+	if lineJoin == Round {
+		dx, dy := x-p1.x, y-p1.y
+		return dx*dx+dy*dy <= w*w
+	}
+	dlx0, dly0 := p0.dy, -p0.dx
+	dlx1, dly1 := p1.dy, -p1.dx
+	dmx := (dlx0 + dlx1) * 0.5
+	dmy := (dly0 + dly1) * 0.5
+	dmr2 := dmx*dmx + dmy*dmy
+	// The outer side of the corner is opposite to the turn.
+	side := float32(1.0)
+	if (p1.dx-p0.dx)*dmx+(p1.dy-p0.dy)*dmy > 0.0 {
+		side = -1.0
+	}
+	ax, ay := p1.x+dlx0*w*side, p1.y+dly0*w*side
+	bx, by := p1.x+dlx1*w*side, p1.y+dly1*w*side
+	if lineJoin == Bevel || dmr2*miterLimit*miterLimit < 1.0 {
+		return pointInPolygon(x, y, p1.x, p1.y, ax, ay, bx, by)
+	}
+	scale := minF(1.0/dmr2, 600.0)
+	mx, my := p1.x+dmx*scale*w*side, p1.y+dmy*scale*w*side
+	return pointInPolygon(x, y, p1.x, p1.y, ax, ay, mx, my, bx, by)
+}
+
+// pointInPolygon returns true if (x,y) is inside of the polygon that has the coordinates of the points in turn.
+func pointInPolygon(x, y float32, coords ...float32) bool {
+	winding := 0
+	for i := 0; i < len(coords); i += 2 {
+		j := (i + 2) % len(coords)
+		winding += edgeCrossing(x, y, coords[i], coords[i+1], coords[j], coords[j+1])
+	}
+	return winding != 0
+}
+
+// edgeCrossing returns 1 if the edge from (x0,y0) to (x1,y1) crosses the ray from (x,y) to the right in the
+// direction of y, -1 if it crosses in the opposite direction, and 0 if it doesn't cross.
+func edgeCrossing(x, y, x0, y0, x1, y1 float32) int {
+	side := (x1-x0)*(y-y0) - (x-x0)*(y1-y0)
+	if y0 <= y {
+		if y1 > y && side > 0.0 {
+			return 1
+		}
+	} else if y1 <= y && side < 0.0 {
+		return -1
+	}
+	return 0
+}
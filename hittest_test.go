@@ -0,0 +1,73 @@
+package nanovgo
+
+import "testing"
+
+func TestIsPointInPath(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.Translate(32, 32)
+	ctx.Rotate(PI / 4)
+	ctx.BeginPath()
+	ctx.Rect(-10, -10, 20, 20)
+	ctx.Rect(-5, -5, 10, 10)
+	if !ctx.IsPointInPath(9, 9) || ctx.IsPointInPath(11, 0) {
+		t.Error("the point should be tested in the local coordinates")
+	}
+	if !ctx.IsPointInPath(0, 0) {
+		t.Error("solid sub-paths should fill the inner one by the non-zero rule")
+	}
+	ctx.SetFillRule(EvenOdd)
+	if ctx.IsPointInPath(0, 0) || !ctx.IsPointInPath(7, 0) {
+		t.Error("the inner sub-path should be a hole by the even-odd rule")
+	}
+
+	ctx.SetFillRule(NonZero)
+	ctx.BeginPath()
+	ctx.Rect(-10, -10, 20, 20)
+	ctx.Rect(-5, -5, 10, 10)
+	ctx.PathWinding(Hole)
+	if ctx.IsPointInPath(0, 0) {
+		t.Error("the hole should be outside of the path")
+	}
+}
+
+func TestIsPointInStroke(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.SetStrokeWidth(4)
+	ctx.BeginPath()
+	ctx.MoveTo(0, 0)
+	ctx.LineTo(20, 0)
+	ctx.LineTo(20, 20)
+
+	for _, c := range []struct {
+		lineCap, lineJoin LineCap
+		x, y              float32
+		expected          bool
+	}{
+		{Butt, Miter, 10, 1.5, true},
+		{Butt, Miter, 10, 2.5, false},
+		{Butt, Miter, -1, 0, false},
+		{Square, Miter, -1.5, 1.5, true},
+		{Round, Miter, -1.5, 1, true},
+		{Round, Miter, -1.5, 1.5, false},
+		{Butt, Miter, 21.9, -1.9, true},
+		{Butt, Bevel, 21.9, -1.9, false},
+		{Butt, Bevel, 20.8, -0.8, true},
+		{Butt, Round, 21.3, -1.3, true},
+		{Butt, Round, 21.7, -1.7, false},
+	} {
+		ctx.SetLineCap(c.lineCap)
+		ctx.SetLineJoin(c.lineJoin)
+		if actual := ctx.IsPointInStroke(c.x, c.y); actual != c.expected {
+			t.Errorf("IsPointInStroke(%f, %f) with cap %d and join %d should be %v", c.x, c.y, c.lineCap, c.lineJoin, c.expected)
+		}
+	}
+
+	ctx.SetLineDash([]float32{5})
+	if !ctx.IsPointInStroke(2, 0) || ctx.IsPointInStroke(7, 0) {
+		t.Error("the gaps of the dashes should be outside of the stroke")
+	}
+	ctx.SetLineDash(nil)
+	if !ctx.IsPointInStroke(7, 0) {
+		t.Error("the path should be flattened again after the dashes")
+	}
+}
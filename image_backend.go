@@ -0,0 +1,839 @@
+package nanovgo
+
+import (
+	"errors"
+	"image"
+	"math"
+)
+
+// NewImageContext makes new NanoVGo context that renders into dst by the software rasterizer.
+// It doesn't need GPU, so it can be used to create images on servers or to test drawing code.
+// dst should have the size of the window size passed to Context.BeginFrame() multiplied by the device pixel ratio.
+func NewImageContext(dst *image.RGBA, flags CreateFlags) (*Context, error) {
+	return NewContextWithRenderer(&imageContext{
+		dst:             dst,
+		flags:           flags,
+		isEdgeAntiAlias: (flags & AntiAlias) != 0,
+	})
+}
+
+type imageTexture struct {
+	id            int
+	width, height int
+	texType       TextureType
+	data          []byte
+	nearest       bool
+}
+
+// toImage converts the texture into image.Image. RGBA textures are alpha-premultiplied like image.RGBA.
+func (t *imageTexture) toImage() image.Image {
+	rect := image.Rect(0, 0, t.width, t.height)
+	if t.texType == TextureRGBA {
+		return &image.RGBA{Pix: t.data, Stride: t.width * 4, Rect: rect}
+	}
+	return &image.Alpha{Pix: t.data, Stride: t.width, Rect: rect}
+}
+
+// imageTextures keeps textures in main memory. It is used by the renderers that don't have GPU textures.
+type imageTextures struct {
+	textures  []*imageTexture
+	textureID int
+}
+
+func (c *imageTextures) findTexture(id int) *imageTexture {
+	for _, texture := range c.textures {
+		if texture.id == id {
+			return texture
+		}
+	}
+	return nil
+}
+
+func (c *imageTextures) allocTexture() *imageTexture {
+	var tex *imageTexture
+	for _, texture := range c.textures {
+		if texture.id == 0 {
+			tex = texture
+			break
+		}
+	}
+	if tex == nil {
+		tex = &imageTexture{}
+		c.textures = append(c.textures, tex)
+	}
+	c.textureID++
+	tex.id = c.textureID
+	return tex
+}
+
+func (c *imageTextures) CreateTexture(texType TextureType, w, h int, data []byte) int {
+	tex := c.allocTexture()
+	tex.width = w
+	tex.height = h
+	tex.texType = texType
+	tex.nearest = false
+
+	bpp := 1
+	if texType == TextureRGBA {
+		bpp = 4
+	}
+	tex.data = make([]byte, w*h*bpp)
+	if data != nil {
+		copy(tex.data, data)
+	}
+	return tex.id
+}
+
+func (c *imageTextures) DeleteTexture(id int) error {
+	tex := c.findTexture(id)
+	if tex == nil {
+		return errors.New("invalid texture in imageTextures.deleteTexture")
+	}
+	tex.id = 0
+	tex.data = nil
+	return nil
+}
+
+func (c *imageTextures) UpdateTexture(image, x, y, w, h int, data []byte) error {
+	tex := c.findTexture(image)
+	if tex == nil {
+		return errors.New("invalid texture in imageTextures.updateTexture")
+	}
+	// Same as the GL backend, updates whole rows of the texture.
+	stride := tex.width
+	if tex.texType == TextureRGBA {
+		stride *= 4
+	}
+	copy(tex.data[y*stride:(y+h)*stride], data[y*stride:])
+	return nil
+}
+
+func (c *imageTextures) GetTextureSize(image int) (int, int, error) {
+	tex := c.findTexture(image)
+	if tex == nil {
+		return -1, -1, errors.New("invalid texture in imageTextures.getTextureSize")
+	}
+	return tex.width, tex.height, nil
+}
+
+// imageFrag is the software version of glFragUniforms
+type imageFrag struct {
+	shaderType   int
+	scissorMat   TransformMatrix
+	scissorExt   [2]float32
+	scissorScale [2]float32
+	paintMat     TransformMatrix
+	innerColor   [4]float32
+	outerColor   [4]float32
+	extent       [2]float32
+	radius       float32
+	feather      float32
+	strokeMult   float32
+	strokeThr    float32
+	texType      int
+	tex          *imageTexture
+	stops        []imageStop
+}
+
+// imageStop is a gradient stop that has the alpha-premultiplied color.
+type imageStop struct {
+	offset float32
+	color  [4]float32
+}
+
+type imageFramebuffer struct {
+	id      int
+	image   int
+	stencil []uint8
+}
+
+type imageContext struct {
+	imageTextures
+	dst     *image.RGBA
+	flags   CreateFlags
+	view    [2]float32
+	stencil []uint8
+
+	framebuffers  []*imageFramebuffer
+	framebufferID int
+	framebuffer   *imageFramebuffer
+	screen        *image.RGBA
+	screenStencil []uint8
+
+	clip        *Clip
+	clipMask    []bool
+	clipMaskOf  *Clip
+	clipMaskDst *image.RGBA
+
+	mask      *imageTexture
+	composite CompositeOperationState
+	blendMode BlendMode
+	fillRule  FillRule
+
+	isEdgeAntiAlias bool
+}
+
+func (c *imageContext) convertPaint(frag *imageFrag, paint *Paint, scissor *Scissor, width, fringe, strokeThr float32) error {
+	frag.innerColor = colorToArray(paint.InnerColor)
+	frag.outerColor = colorToArray(paint.OuterColor)
+
+	if scissor.Extent[0] < -0.5 || scissor.Extent[1] < -0.5 {
+		frag.scissorMat = TransformMatrix{}
+		frag.scissorExt = [2]float32{1.0, 1.0}
+		frag.scissorScale = [2]float32{1.0, 1.0}
+	} else {
+		xform := &scissor.Xform
+		frag.scissorMat = xform.Inverse()
+		frag.scissorExt = scissor.Extent
+		frag.scissorScale = [2]float32{
+			sqrtF(xform[0]*xform[0]+xform[2]*xform[2]) / fringe,
+			sqrtF(xform[1]*xform[1]+xform[3]*xform[3]) / fringe,
+		}
+	}
+	frag.extent = paint.Extent
+	frag.strokeMult = (width*0.5 + fringe*0.5) / fringe
+	frag.strokeThr = strokeThr
+	frag.paintMat = paint.Xform.Inverse()
+
+	if paint.Image != 0 {
+		tex := c.findTexture(paint.Image)
+		if tex == nil {
+			return errors.New("invalid texture in imageContext.convertPaint")
+		}
+		frag.shaderType = nsvgShaderFILLIMG
+		frag.tex = tex
+		if tex.texType == TextureRGBA {
+			// Pixels come from image.RGBA, which is already alpha-premultiplied.
+			frag.texType = 0
+		} else {
+			frag.texType = 2
+		}
+	} else {
+		frag.shaderType = nsvgShaderFILLGRAD
+		frag.radius = paint.Radius
+		frag.feather = paint.Feather
+		if len(paint.Stops) > 0 || paint.Conic {
+			frag.shaderType = nsvgShaderFILLRAMP
+			if paint.Conic {
+				frag.shaderType = nsvgShaderFILLCONIC
+			}
+			stops := paint.gradientStops()
+			frag.stops = make([]imageStop, len(stops))
+			for i, stop := range stops {
+				frag.stops[i] = imageStop{offset: stop.Offset, color: colorToArray(stop.Color)}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *imageContext) EdgeAntiAlias() bool {
+	return c.isEdgeAntiAlias
+}
+
+func (c *imageContext) Create() error {
+	if c.dst == nil {
+		return errors.New("destination image is nil")
+	}
+	size := c.dst.Rect.Size()
+	c.stencil = make([]uint8, size.X*size.Y)
+	return nil
+}
+
+func (c *imageContext) Viewport(width, height int) {
+	c.view[0] = float32(width)
+	c.view[1] = float32(height)
+}
+
+func (c *imageContext) Cancel() {
+}
+
+func (c *imageContext) Flush() {
+}
+
+func (c *imageContext) Fill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []Path) {
+	c.prepareClip()
+	var frag imageFrag
+	if c.convertPaint(&frag, paint, scissor, fringe, fringe, -1.0) != nil {
+		return
+	}
+
+	if len(paths) == 1 && paths[0].Convex {
+		// Convex shapes are drawn directly without stencil.
+		for i := range paths {
+			path := &paths[i]
+			c.drawTriangleFan(path.Fills, true, func(x, y int, fx, fy, u, v float32) {
+				c.blendFragment(x, y, &frag, fx, fy, u, v)
+			})
+		}
+		if c.flags&AntiAlias != 0 {
+			for i := range paths {
+				c.drawTriangleStrip(paths[i].Strokes, true, func(x, y int, fx, fy, u, v float32) {
+					c.blendFragment(x, y, &frag, fx, fy, u, v)
+				})
+			}
+		}
+		return
+	}
+
+	// Draw shapes into the stencil buffer by the fill rule.
+	width := c.dst.Rect.Dx()
+	for i := range paths {
+		fills := paths[i].Fills
+		for j := 2; j < len(fills); j++ {
+			c.rasterTriangle(&fills[0], &fills[j-1], &fills[j], false, func(x, y int, front bool, fx, fy, u, v float32) {
+				if c.fillRule == EvenOdd {
+					c.stencil[x+y*width] ^= 1
+				} else if front {
+					c.stencil[x+y*width]++
+				} else {
+					c.stencil[x+y*width]--
+				}
+			})
+		}
+	}
+
+	// Draw anti-aliased pixels
+	if c.flags&AntiAlias != 0 {
+		for i := range paths {
+			c.drawTriangleStrip(paths[i].Strokes, true, func(x, y int, fx, fy, u, v float32) {
+				if c.stencil[x+y*width] == 0 {
+					c.blendFragment(x, y, &frag, fx, fy, u, v)
+				}
+			})
+		}
+	}
+
+	// Draw fill and clear the stencil buffer
+	quad := [4]Vertex{
+		{X: bounds[0], Y: bounds[3], U: 0.5, V: 1.0},
+		{X: bounds[2], Y: bounds[3], U: 0.5, V: 1.0},
+		{X: bounds[2], Y: bounds[1], U: 0.5, V: 1.0},
+		{X: bounds[0], Y: bounds[1], U: 0.5, V: 1.0},
+	}
+	c.drawTriangleFan(quad[:], true, func(x, y int, fx, fy, u, v float32) {
+		index := x + y*width
+		if c.stencil[index] != 0 {
+			c.blendFragment(x, y, &frag, fx, fy, u, v)
+			c.stencil[index] = 0
+		}
+	})
+}
+
+func (c *imageContext) Stroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []Path) {
+	c.prepareClip()
+	if c.flags&StencilStrokes != 0 {
+		var frag0, frag1 imageFrag
+		if c.convertPaint(&frag0, paint, scissor, strokeWidth, fringe, -1.0) != nil {
+			return
+		}
+		c.convertPaint(&frag1, paint, scissor, strokeWidth, fringe, 1.0-0.5/255.0)
+		width := c.dst.Rect.Dx()
+
+		// Fill the stroke base without overlap
+		for i := range paths {
+			c.drawTriangleStrip(paths[i].Strokes, true, func(x, y int, fx, fy, u, v float32) {
+				index := x + y*width
+				if c.stencil[index] == 0 && c.blendFragment(x, y, &frag1, fx, fy, u, v) {
+					c.stencil[index]++
+				}
+			})
+		}
+		// Draw anti-aliased pixels.
+		for i := range paths {
+			c.drawTriangleStrip(paths[i].Strokes, true, func(x, y int, fx, fy, u, v float32) {
+				if c.stencil[x+y*width] == 0 {
+					c.blendFragment(x, y, &frag0, fx, fy, u, v)
+				}
+			})
+		}
+		// Clear stencil buffer.
+		for i := range paths {
+			c.drawTriangleStrip(paths[i].Strokes, true, func(x, y int, fx, fy, u, v float32) {
+				c.stencil[x+y*width] = 0
+			})
+		}
+	} else {
+		var frag imageFrag
+		if c.convertPaint(&frag, paint, scissor, strokeWidth, fringe, -1.0) != nil {
+			return
+		}
+		for i := range paths {
+			c.drawTriangleStrip(paths[i].Strokes, true, func(x, y int, fx, fy, u, v float32) {
+				c.blendFragment(x, y, &frag, fx, fy, u, v)
+			})
+		}
+	}
+}
+
+func (c *imageContext) Triangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+	c.prepareClip()
+	var frag imageFrag
+	if c.convertPaint(&frag, paint, scissor, 1.0, 1.0, -1.0) != nil {
+		return
+	}
+	frag.shaderType = nsvgShaderIMG
+	for i := 2; i < len(vertexes); i += 3 {
+		c.rasterTriangle(&vertexes[i-2], &vertexes[i-1], &vertexes[i], true, func(x, y int, front bool, fx, fy, u, v float32) {
+			c.blendFragment(x, y, &frag, fx, fy, u, v)
+		})
+	}
+}
+
+func (c *imageContext) TriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+	c.prepareClip()
+	var frag imageFrag
+	if c.convertPaint(&frag, paint, scissor, 1.0, 1.0, -1.0) != nil {
+		return
+	}
+	frag.shaderType = nsvgShaderIMG
+	c.drawTriangleStrip(vertexes, true, func(x, y int, fx, fy, u, v float32) {
+		c.blendFragment(x, y, &frag, fx, fy, u, v)
+	})
+}
+
+func (c *imageContext) Delete() {
+	c.BindFramebuffer(0)
+	c.textures = nil
+	c.framebuffers = nil
+	c.stencil = nil
+}
+
+func (c *imageContext) SetClip(clip *Clip) {
+	c.clip = clip
+}
+
+// prepareClip makes the mask of the clip region for the current destination image.
+func (c *imageContext) prepareClip() {
+	if c.clip == nil || (c.clipMaskOf == c.clip && c.clipMaskDst == c.dst) {
+		return
+	}
+	size := c.dst.Rect.Size()
+	if len(c.clipMask) != size.X*size.Y {
+		c.clipMask = make([]bool, size.X*size.Y)
+	}
+	winding := make([]int, size.X*size.Y)
+	for i, clip := range c.clip.chain() {
+		for j := range winding {
+			winding[j] = 0
+		}
+		for j := range clip.Paths {
+			fills := clip.Paths[j].Fills
+			for k := 2; k < len(fills); k++ {
+				c.rasterTriangle(&fills[0], &fills[k-1], &fills[k], false, func(x, y int, front bool, fx, fy, u, v float32) {
+					if clip.FillRule == EvenOdd {
+						winding[x+y*size.X] ^= 1
+					} else if front {
+						winding[x+y*size.X]++
+					} else {
+						winding[x+y*size.X]--
+					}
+				})
+			}
+		}
+		for j, w := range winding {
+			c.clipMask[j] = w != 0 && (i == 0 || c.clipMask[j])
+		}
+	}
+	c.clipMaskOf = c.clip
+	c.clipMaskDst = c.dst
+}
+
+func (c *imageContext) SetMask(image int) {
+	if image == 0 {
+		c.mask = nil
+	} else {
+		c.mask = c.findTexture(image)
+	}
+}
+
+// maskAlpha samples the mask stretched over the view at the pixel center.
+func (c *imageContext) maskAlpha(fx, fy float32) float32 {
+	color := c.mask.sample(fx/c.view[0], fy/c.view[1], 0)
+	if c.mask.texType == TextureRGBA {
+		return color[3]
+	}
+	return color[0]
+}
+
+func (c *imageContext) findFramebuffer(id int) *imageFramebuffer {
+	for _, fb := range c.framebuffers {
+		if fb.id == id {
+			return fb
+		}
+	}
+	return nil
+}
+
+func (c *imageContext) CreateFramebuffer(w, h int, flags ImageFlags) (int, int, error) {
+	image := c.CreateTexture(TextureRGBA, w, h, nil)
+	c.findTexture(image).nearest = flags&ImageNearest != 0
+
+	var fb *imageFramebuffer
+	for _, framebuffer := range c.framebuffers {
+		if framebuffer.id == 0 {
+			fb = framebuffer
+			break
+		}
+	}
+	if fb == nil {
+		fb = &imageFramebuffer{}
+		c.framebuffers = append(c.framebuffers, fb)
+	}
+	c.framebufferID++
+	fb.id = c.framebufferID
+	fb.image = image
+	fb.stencil = make([]uint8, w*h)
+	return fb.id, image, nil
+}
+
+func (c *imageContext) BindFramebuffer(id int) error {
+	if id == 0 {
+		if c.framebuffer != nil {
+			c.dst = c.screen
+			c.stencil = c.screenStencil
+			c.framebuffer = nil
+		}
+		return nil
+	}
+	fb := c.findFramebuffer(id)
+	if fb == nil {
+		return errors.New("invalid framebuffer in imageContext.BindFramebuffer")
+	}
+	tex := c.findTexture(fb.image)
+	if tex == nil {
+		return errors.New("framebuffer image is deleted in imageContext.BindFramebuffer")
+	}
+	if c.framebuffer == nil {
+		c.screen = c.dst
+		c.screenStencil = c.stencil
+	}
+	c.dst = tex.toImage().(*image.RGBA)
+	c.stencil = fb.stencil
+	c.framebuffer = fb
+	return nil
+}
+
+func (c *imageContext) DeleteFramebuffer(id int) error {
+	fb := c.findFramebuffer(id)
+	if fb == nil {
+		return errors.New("invalid framebuffer in imageContext.DeleteFramebuffer")
+	}
+	if c.framebuffer == fb {
+		c.BindFramebuffer(0)
+	}
+	c.DeleteTexture(fb.image)
+	fb.id = 0
+	fb.image = 0
+	fb.stencil = nil
+	return nil
+}
+
+func (c *imageContext) drawTriangleFan(vertexes []Vertex, cull bool, fragment func(x, y int, fx, fy, u, v float32)) {
+	for i := 2; i < len(vertexes); i++ {
+		c.rasterTriangle(&vertexes[0], &vertexes[i-1], &vertexes[i], cull, func(x, y int, front bool, fx, fy, u, v float32) {
+			fragment(x, y, fx, fy, u, v)
+		})
+	}
+}
+
+func (c *imageContext) drawTriangleStrip(vertexes []Vertex, cull bool, fragment func(x, y int, fx, fy, u, v float32)) {
+	for i := 2; i < len(vertexes); i++ {
+		// Keep the winding of odd triangles same as even ones like OpenGL does.
+		v0, v1 := &vertexes[i-2], &vertexes[i-1]
+		if i%2 == 1 {
+			v0, v1 = v1, v0
+		}
+		c.rasterTriangle(v0, v1, &vertexes[i], cull, func(x, y int, front bool, fx, fy, u, v float32) {
+			fragment(x, y, fx, fy, u, v)
+		})
+	}
+}
+
+// rasterTriangle calls fragment for each pixel whose center is inside of the triangle.
+// x, y are the pixel position relative to the destination image, and fx, fy are the pixel center in the view
+// coordinates. u, v are interpolated texture coordinates. Triangles are front facing when they are counter-clockwise
+// in the OpenGL window coordinates (clockwise on screen), and back facing triangles are skipped if cull is true.
+func (c *imageContext) rasterTriangle(v0, v1, v2 *Vertex, cull bool, fragment func(x, y int, front bool, fx, fy, u, v float32)) {
+	size := c.dst.Rect.Size()
+	if c.view[0] <= 0 || c.view[1] <= 0 {
+		return
+	}
+	scaleX := float32(size.X) / c.view[0]
+	scaleY := float32(size.Y) / c.view[1]
+
+	x0, y0 := v0.X*scaleX, v0.Y*scaleY
+	x1, y1 := v1.X*scaleX, v1.Y*scaleY
+	x2, y2 := v2.X*scaleX, v2.Y*scaleY
+
+	area := (x1-x0)*(y2-y0) - (x2-x0)*(y1-y0)
+	if area == 0 {
+		return
+	}
+	front := area < 0
+	if cull && !front {
+		return
+	}
+	// Make the edge functions positive inside of the triangle.
+	if area < 0 {
+		x1, y1, x2, y2 = x2, y2, x1, y1
+		v1, v2 = v2, v1
+		area = -area
+	}
+
+	minX := maxI(0, int(math.Floor(float64(minF(x0, minF(x1, x2))))))
+	minY := maxI(0, int(math.Floor(float64(minF(y0, minF(y1, y2))))))
+	maxX := minI(size.X-1, int(math.Ceil(float64(maxF(x0, maxF(x1, x2))))))
+	maxY := minI(size.Y-1, int(math.Ceil(float64(maxF(y0, maxF(y1, y2))))))
+
+	// Pixels on the shared edge are owned by only one of the triangles.
+	tie0 := isOwnerEdge(x2-x1, y2-y1)
+	tie1 := isOwnerEdge(x0-x2, y0-y2)
+	tie2 := isOwnerEdge(x1-x0, y1-y0)
+	invArea := 1.0 / area
+
+	for y := minY; y <= maxY; y++ {
+		py := float32(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := float32(x) + 0.5
+			w0 := (x2-x1)*(py-y1) - (y2-y1)*(px-x1)
+			w1 := (x0-x2)*(py-y2) - (y0-y2)*(px-x2)
+			w2 := (x1-x0)*(py-y0) - (y1-y0)*(px-x0)
+			if w0 < 0 || w1 < 0 || w2 < 0 {
+				continue
+			}
+			if (w0 == 0 && !tie0) || (w1 == 0 && !tie1) || (w2 == 0 && !tie2) {
+				continue
+			}
+			l0 := w0 * invArea
+			l1 := w1 * invArea
+			l2 := w2 * invArea
+			u := v0.U*l0 + v1.U*l1 + v2.U*l2
+			v := v0.V*l0 + v1.V*l1 + v2.V*l2
+			fragment(x, y, front, px/scaleX, py/scaleY, u, v)
+		}
+	}
+}
+
+func isOwnerEdge(dx, dy float32) bool {
+	return dy > 0 || (dy == 0 && dx > 0)
+}
+
+// blendFragment shades the pixel and blends it into the destination image.
+// It returns false if the fragment is discarded.
+func (c *imageContext) blendFragment(x, y int, frag *imageFrag, fx, fy, u, v float32) bool {
+	if c.clip != nil && !c.clipMask[x+y*c.dst.Rect.Dx()] {
+		return false
+	}
+	color, ok := c.shadeFragment(frag, fx, fy, u, v)
+	if !ok {
+		return false
+	}
+	if c.mask != nil {
+		alpha := c.maskAlpha(fx, fy)
+		for i := 0; i < 4; i++ {
+			color[i] *= alpha
+		}
+	}
+	min := c.dst.Rect.Min
+	offset := c.dst.PixOffset(min.X+x, min.Y+y)
+	pix := c.dst.Pix[offset : offset+4 : offset+4]
+	if c.blendMode != BlendNormal {
+		var dst [4]float32
+		for i := 0; i < 4; i++ {
+			dst[i] = float32(pix[i]) / 255.0
+		}
+		result := blendColors(c.blendMode, color, dst)
+		for i := 0; i < 4; i++ {
+			pix[i] = uint8(clampF(result[i]*255.0+0.5, 0.0, 255.0))
+		}
+		return true
+	}
+	if !c.composite.isSourceOver() {
+		c.compositeFragment(pix, color)
+		return true
+	}
+	invAlpha := 1.0 - color[3]
+	for i := 0; i < 4; i++ {
+		value := color[i]*255.0 + float32(pix[i])*invAlpha
+		pix[i] = uint8(clampF(value+0.5, 0.0, 255.0))
+	}
+	return true
+}
+
+func (c *imageContext) SetCompositeOperation(op CompositeOperationState) {
+	c.composite = op
+}
+
+func (c *imageContext) SetBlendMode(mode BlendMode) {
+	c.blendMode = mode
+}
+
+func (c *imageContext) SetFillRule(rule FillRule) {
+	c.fillRule = rule
+}
+
+// compositeFragment blends the color into the pixel by the blend factors like glBlendFuncSeparate().
+func (c *imageContext) compositeFragment(pix []uint8, color [4]float32) {
+	op := c.composite
+	srcRGB, dstRGB, srcAlpha, dstAlpha := op.SrcRGB, op.DstRGB, op.SrcAlpha, op.DstAlpha
+	if !srcRGB.isValid() || !dstRGB.isValid() || !srcAlpha.isValid() || !dstAlpha.isValid() {
+		srcRGB, dstRGB, srcAlpha, dstAlpha = One, OneMinusSrcAlpha, One, OneMinusSrcAlpha
+	}
+	var dst [4]float32
+	for i := 0; i < 4; i++ {
+		dst[i] = float32(pix[i]) / 255.0
+	}
+	for i := 0; i < 4; i++ {
+		srcFactor, dstFactor := srcRGB, dstRGB
+		if i == 3 {
+			srcFactor, dstFactor = srcAlpha, dstAlpha
+		}
+		value := color[i]*srcFactor.value(color, dst, i) + dst[i]*dstFactor.value(color, dst, i)
+		pix[i] = uint8(clampF(value*255.0+0.5, 0.0, 255.0))
+	}
+}
+
+// shadeFragment is the software version of fillFragmentShader.
+func (c *imageContext) shadeFragment(frag *imageFrag, fx, fy, u, v float32) ([4]float32, bool) {
+	var result [4]float32
+	scissor := frag.scissorMask(fx, fy)
+	var strokeAlpha float32 = 1.0
+	if c.isEdgeAntiAlias {
+		strokeAlpha = frag.strokeMask(u, v)
+	}
+
+	switch frag.shaderType {
+	case nsvgShaderFILLGRAD:
+		px, py := frag.paintMat.TransformPoint(fx, fy)
+		d := clampF((sdRoundRect(px, py, frag.extent[0], frag.extent[1], frag.radius)+frag.feather*0.5)/frag.feather, 0.0, 1.0)
+		alpha := strokeAlpha * scissor
+		for i := 0; i < 4; i++ {
+			result[i] = (frag.innerColor[i] + (frag.outerColor[i]-frag.innerColor[i])*d) * alpha
+		}
+	case nsvgShaderFILLIMG:
+		px, py := frag.paintMat.TransformPoint(fx, fy)
+		color := frag.tex.sample(px/frag.extent[0], py/frag.extent[1], frag.texType)
+		alpha := strokeAlpha * scissor
+		for i := 0; i < 4; i++ {
+			result[i] = color[i] * frag.innerColor[i] * alpha
+		}
+	case nsvgShaderFILLRAMP:
+		px, py := frag.paintMat.TransformPoint(fx, fy)
+		d := clampF((sdRoundRect(px, py, frag.extent[0], frag.extent[1], frag.radius)+frag.feather*0.5)/frag.feather, 0.0, 1.0)
+		color := frag.stopColor(d)
+		alpha := strokeAlpha * scissor
+		for i := 0; i < 4; i++ {
+			result[i] = color[i] * alpha
+		}
+	case nsvgShaderFILLCONIC:
+		px, py := frag.paintMat.TransformPoint(fx, fy)
+		color := frag.stopColor(conicOffset(px, py))
+		alpha := strokeAlpha * scissor
+		for i := 0; i < 4; i++ {
+			result[i] = color[i] * alpha
+		}
+	case nsvgShaderSIMPLE:
+		result = [4]float32{1, 1, 1, 1}
+	case nsvgShaderIMG:
+		color := frag.tex.sample(u, v, frag.texType)
+		for i := 0; i < 4; i++ {
+			result[i] = color[i] * scissor * frag.innerColor[i]
+		}
+	}
+	if c.isEdgeAntiAlias && strokeAlpha < frag.strokeThr {
+		return result, false
+	}
+	return result, true
+}
+
+func (f *imageFrag) scissorMask(x, y float32) float32 {
+	sx, sy := f.scissorMat.TransformPoint(x, y)
+	sx = 0.5 - (absF(sx)-f.scissorExt[0])*f.scissorScale[0]
+	sy = 0.5 - (absF(sy)-f.scissorExt[1])*f.scissorScale[1]
+	return clampF(sx, 0.0, 1.0) * clampF(sy, 0.0, 1.0)
+}
+
+// stopColor interpolates the colors of the gradient stops at the offset.
+func (f *imageFrag) stopColor(offset float32) [4]float32 {
+	stops := f.stops
+	if offset <= stops[0].offset {
+		return stops[0].color
+	}
+	for i := 1; i < len(stops); i++ {
+		if offset < stops[i].offset {
+			t := (offset - stops[i-1].offset) / (stops[i].offset - stops[i-1].offset)
+			var result [4]float32
+			for j := 0; j < 4; j++ {
+				result[j] = stops[i-1].color[j] + (stops[i].color[j]-stops[i-1].color[j])*t
+			}
+			return result
+		}
+	}
+	return stops[len(stops)-1].color
+}
+
+func (f *imageFrag) strokeMask(u, v float32) float32 {
+	return minF(1.0, (1.0-absF(u*2.0-1.0))*f.strokeMult) * minF(1.0, v)
+}
+
+// sample reads the texture with bilinear or nearest filtering and clamp-to-edge wrapping.
+func (t *imageTexture) sample(s, u float32, texType int) [4]float32 {
+	var result [4]float32
+	if t == nil || t.width == 0 || t.height == 0 {
+		return result
+	}
+	if t.nearest {
+		x := clampI(int(math.Floor(float64(s*float32(t.width)))), 0, t.width-1)
+		y := clampI(int(math.Floor(float64(u*float32(t.height)))), 0, t.height-1)
+		result = t.texel(x, y)
+	} else {
+		result = t.bilinear(s*float32(t.width)-0.5, u*float32(t.height)-0.5)
+	}
+	switch texType {
+	case 1:
+		result = [4]float32{result[0] * result[3], result[1] * result[3], result[2] * result[3], result[3]}
+	case 2:
+		result = [4]float32{result[0], result[0], result[0], result[0]}
+	}
+	return result
+}
+
+func (t *imageTexture) bilinear(x, y float32) [4]float32 {
+	var result [4]float32
+	fx := float32(math.Floor(float64(x)))
+	fy := float32(math.Floor(float64(y)))
+	ax := x - fx
+	ay := y - fy
+	x0 := clampI(int(fx), 0, t.width-1)
+	x1 := clampI(int(fx)+1, 0, t.width-1)
+	y0 := clampI(int(fy), 0, t.height-1)
+	y1 := clampI(int(fy)+1, 0, t.height-1)
+
+	c00 := t.texel(x0, y0)
+	c10 := t.texel(x1, y0)
+	c01 := t.texel(x0, y1)
+	c11 := t.texel(x1, y1)
+	for i := 0; i < 4; i++ {
+		top := c00[i] + (c10[i]-c00[i])*ax
+		bottom := c01[i] + (c11[i]-c01[i])*ax
+		result[i] = top + (bottom-top)*ay
+	}
+	return result
+}
+
+func (t *imageTexture) texel(x, y int) [4]float32 {
+	if t.texType == TextureRGBA {
+		offset := (x + y*t.width) * 4
+		pix := t.data[offset : offset+4 : offset+4]
+		return [4]float32{float32(pix[0]) / 255.0, float32(pix[1]) / 255.0, float32(pix[2]) / 255.0, float32(pix[3]) / 255.0}
+	}
+	l := float32(t.data[x+y*t.width]) / 255.0
+	return [4]float32{l, l, l, 1.0}
+}
+
+func sdRoundRect(x, y, extX, extY, radius float32) float32 {
+	dx := absF(x) - (extX - radius)
+	dy := absF(y) - (extY - radius)
+	return minF(maxF(dx, dy), 0.0) + sqrtF(maxF(dx, 0)*maxF(dx, 0)+maxF(dy, 0)*maxF(dy, 0)) - radius
+}
@@ -0,0 +1,163 @@
+package nanovgo
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func newTestImageContext(t *testing.T, w, h int, flags CreateFlags) (*Context, *image.RGBA) {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	ctx, err := NewImageContext(dst, flags)
+	if err != nil {
+		t.Fatalf("NewImageContext() failed: %v", err)
+	}
+	ctx.BeginFrame(w, h, 1.0)
+	return ctx, dst
+}
+
+func checkPixel(t *testing.T, dst *image.RGBA, x, y int, expected color.RGBA) {
+	actual := dst.RGBAAt(x, y)
+	diff := func(a, b uint8) int {
+		if a > b {
+			return int(a - b)
+		}
+		return int(b - a)
+	}
+	if diff(actual.R, expected.R) > 2 || diff(actual.G, expected.G) > 2 || diff(actual.B, expected.B) > 2 || diff(actual.A, expected.A) > 2 {
+		t.Errorf("pixel at (%d, %d) should be %v, but %v", x, y, expected, actual)
+	}
+}
+
+var (
+	transparent = color.RGBA{}
+	red         = color.RGBA{R: 255, A: 255}
+	blue        = color.RGBA{B: 255, A: 255}
+	green       = color.RGBA{G: 255, A: 255}
+	white       = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+func TestImageContextFillRect(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 20, 20)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 20, 20, red)
+	checkPixel(t, dst, 10, 10, red)
+	checkPixel(t, dst, 29, 29, red)
+	checkPixel(t, dst, 5, 5, transparent)
+	checkPixel(t, dst, 30, 30, transparent)
+}
+
+func TestImageContextFillConcave(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.Rect(8, 8, 48, 48)
+	ctx.Rect(24, 24, 16, 16)
+	ctx.PathWinding(Hole)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 12, 12, blue)
+	checkPixel(t, dst, 32, 32, transparent)
+	checkPixel(t, dst, 50, 50, blue)
+}
+
+func TestImageContextStroke(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginPath()
+	ctx.MoveTo(10, 32)
+	ctx.LineTo(54, 32)
+	ctx.SetStrokeWidth(4)
+	ctx.SetStrokeColor(red)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 32, 32, red)
+	checkPixel(t, dst, 32, 31, red)
+	checkPixel(t, dst, 32, 40, transparent)
+}
+
+func TestImageContextGradient(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 16, 0)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 16)
+	ctx.SetFillPaint(LinearGradient(0, 0, 64, 0, color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	prev := -1
+	for x := 0; x < 64; x++ {
+		r := int(dst.RGBAAt(x, 8).R)
+		if r < prev {
+			t.Fatalf("gradient should increase from left to right, but %d < %d at %d", r, prev, x)
+		}
+		prev = r
+	}
+	if dst.RGBAAt(0, 8).R > 8 || dst.RGBAAt(63, 8).R < 247 {
+		t.Errorf("gradient should be from black to white, but %v - %v", dst.RGBAAt(0, 8), dst.RGBAAt(63, 8))
+	}
+}
+
+func TestImageContextScissor(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.Scissor(0, 0, 32, 64)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 64)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 16, 16, red)
+	checkPixel(t, dst, 48, 16, transparent)
+}
+
+func TestImageContextImagePattern(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 32, 32, 0)
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.SetRGBA(0, 0, red)
+	src.SetRGBA(1, 0, blue)
+	img := ctx.CreateImage(src)
+
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 32, 32)
+	ctx.SetFillPaint(ImagePattern(0, 0, 32, 32, 0, img, 1.0))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 2, 16, red)
+	checkPixel(t, dst, 29, 16, blue)
+}
+
+func TestImageContextText(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 128, 32, AntiAlias)
+	font := ctx.CreateFont("sans", "sample/Roboto-Regular.ttf")
+	if font == -1 {
+		t.Skip("font file is not available")
+	}
+	ctx.SetFontFace("sans")
+	ctx.SetFontSize(24)
+	ctx.SetFillColor(red)
+	ctx.Text(4, 24, "Hello")
+	ctx.EndFrame()
+
+	covered := 0
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 128; x++ {
+			c := dst.RGBAAt(x, y)
+			if c.A != 0 {
+				covered++
+				if c.G != 0 || c.B != 0 {
+					t.Fatalf("text pixel should be red, but %v", c)
+				}
+			}
+		}
+	}
+	if covered == 0 {
+		t.Error("text should be rendered")
+	}
+}
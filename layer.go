@@ -0,0 +1,148 @@
+package nanovgo
+
+import (
+	"image/color"
+)
+
+// nvgLayer is an offscreen drawing target started by Context.BeginLayer().
+type nvgLayer struct {
+	fb      *Framebuffer
+	parent  *Framebuffer
+	corners [8]float32
+	alpha   float32
+}
+
+// BeginLayer starts a layer. Following drawing goes into an offscreen image until EndLayer() is called,
+// and the image is composited into the previous target at once with the alpha.
+// Overlapping shapes inside of the layer are not blended twice, so it is useful to fade a group of shapes.
+// x, y, w, h are the bounds of the layer, and they are transformed by the current transform.
+// The current render state is saved like Save(), and it is restored by EndLayer().
+// The offscreen images are reused by the following layers, and they are deleted by Context.Delete().
+// If the renderer doesn't support framebuffers, the alpha is applied to each shape like SetGlobalAlpha().
+func (c *Context) BeginLayer(x, y, w, h, alpha float32) {
+	if c.recorder != nil {
+		defer c.record(OpBeginLayer, x, y, w, h, alpha)()
+	}
+	layer := nvgLayer{
+		parent: c.framebuffer,
+		alpha:  alpha,
+	}
+	xform := c.getState().xform
+	layer.corners[0], layer.corners[1] = xform.TransformPoint(x, y)
+	layer.corners[2], layer.corners[3] = xform.TransformPoint(x, y+h)
+	layer.corners[4], layer.corners[5] = xform.TransformPoint(x+w, y+h)
+	layer.corners[6], layer.corners[7] = xform.TransformPoint(x+w, y)
+
+	fbWidth := int(float32(c.windowWidth)*c.devicePxRatio + 0.5)
+	fbHeight := int(float32(c.windowHeight)*c.devicePxRatio + 0.5)
+	if fbWidth > 0 && fbHeight > 0 {
+		layer.fb = c.layerFramebuffer(fbWidth, fbHeight)
+	}
+	c.layers = append(c.layers, layer)
+	c.Save()
+	if layer.fb == nil {
+		c.getState().alpha *= alpha
+	}
+}
+
+// EndLayer ends the layer started by BeginLayer(), and composites it into the previous target.
+// Context.EndFrame() ends the layers that are not ended yet.
+func (c *Context) EndLayer() {
+	if len(c.layers) == 0 {
+		return
+	}
+	if c.recorder != nil {
+		defer c.record(OpEndLayer)()
+	}
+	layer := c.layers[len(c.layers)-1]
+	c.layers = c.layers[:len(c.layers)-1]
+	c.Restore()
+	if layer.fb == nil {
+		return
+	}
+	c.renderer.Flush()
+	c.BindFramebuffer(layer.parent)
+
+	// Draw the layer image by the view coordinates without breaking the current path.
+	commands := append([]float32(nil), c.commands...)
+	commandX, commandY := c.commandX, c.commandY
+	c.Save()
+	c.Reset()
+	c.BeginPath()
+	c.MoveTo(layer.corners[0], layer.corners[1])
+	for i := 2; i < len(layer.corners); i += 2 {
+		c.LineTo(layer.corners[i], layer.corners[i+1])
+	}
+	c.ClosePath()
+	c.SetFillPaint(ImagePattern(0, 0, float32(c.windowWidth), float32(c.windowHeight), 0, layer.fb.Image(), layer.alpha))
+	c.Fill()
+	c.Restore()
+	c.releaseLayerFramebuffer(layer.fb)
+
+	c.commands = append(c.commands[:0], commands...)
+	c.commandX, c.commandY = commandX, commandY
+	c.cache.clearPathCache()
+}
+
+// layerFramebuffer binds a transparent framebuffer for a layer. The framebuffers of the ended layers are
+// reused if they have the same size, so layers don't allocate framebuffers every frame.
+// It returns nil if the renderer doesn't support framebuffers.
+func (c *Context) layerFramebuffer(w, h int) *Framebuffer {
+	var fb *Framebuffer
+	for len(c.layerPool) > 0 && fb == nil {
+		fb = c.layerPool[len(c.layerPool)-1]
+		c.layerPool = c.layerPool[:len(c.layerPool)-1]
+		if width, height := fb.Size(); width != w || height != h {
+			// The window was resized.
+			c.DeleteFramebuffer(fb)
+			fb = nil
+		}
+	}
+	reused := fb != nil
+	if !reused {
+		var err error
+		if fb, err = c.CreateFramebuffer(w, h, 0); err != nil {
+			return nil
+		}
+	}
+	// The draw calls are issued to the target that is bound at the flush.
+	c.renderer.Flush()
+	c.BindFramebuffer(fb)
+	if reused {
+		// Clear the previous content by copying transparent. The draw call is issued before the layer's.
+		commands := append([]float32(nil), c.commands...)
+		commandX, commandY := c.commandX, c.commandY
+		c.Save()
+		c.Reset()
+		c.SetGlobalCompositeOperation(Copy)
+		c.SetFillColor(color.RGBA{})
+		c.BeginPath()
+		c.Rect(-1, -1, float32(c.windowWidth)+2, float32(c.windowHeight)+2)
+		c.Fill()
+		c.Restore()
+		c.commands = append(c.commands[:0], commands...)
+		c.commandX, c.commandY = commandX, commandY
+		c.cache.clearPathCache()
+	}
+	return fb
+}
+
+// releaseLayerFramebuffer keeps the framebuffer of the ended layer for the next layers. The framebuffers
+// are cleared by the copy operation, so renderers that don't support composite operations delete them.
+func (c *Context) releaseLayerFramebuffer(fb *Framebuffer) {
+	if _, ok := c.renderer.(CompositeRenderer); ok {
+		// The queued draw calls that use the image are flushed before the framebuffer is bound again.
+		c.layerPool = append(c.layerPool, fb)
+		return
+	}
+	c.renderer.Flush()
+	c.DeleteFramebuffer(fb)
+}
+
+// deleteLayerFramebuffers deletes the framebuffers kept for the layers.
+func (c *Context) deleteLayerFramebuffers() {
+	for _, fb := range c.layerPool {
+		c.DeleteFramebuffer(fb)
+	}
+	c.layerPool = nil
+}
@@ -0,0 +1,114 @@
+package nanovgo
+
+import (
+	"image/color"
+	"testing"
+)
+
+func drawOverlappedRects(ctx *Context) {
+	ctx.SetFillColor(red)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 20, 20)
+	ctx.Fill()
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 20, 20)
+	ctx.Fill()
+}
+
+func TestLayerGroupOpacity(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.Translate(4, 4)
+	ctx.BeginLayer(0, 0, 40, 40, 0.5)
+	drawOverlappedRects(ctx)
+	ctx.EndLayer()
+	ctx.EndFrame()
+
+	half := color.RGBA{R: 128, A: 128}
+	checkPixel(t, dst, 8, 8, half)
+	// The overlapped area is not blended twice.
+	checkPixel(t, dst, 18, 18, half)
+	checkPixel(t, dst, 30, 30, half)
+	checkPixel(t, dst, 40, 10, transparent)
+}
+
+func TestLayerBounds(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginLayer(0, 0, 15, 64, 1.0)
+	drawOverlappedRects(ctx)
+	// Open layers are ended by EndFrame().
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 5, 5, red)
+	checkPixel(t, dst, 25, 25, transparent)
+}
+
+func TestLayerFallback(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+	ctx.renderer = &testRenderer{}
+	ctx.BeginLayer(0, 0, 16, 16, 0.5)
+	if alpha := ctx.getState().alpha; alpha != 0.5 {
+		t.Errorf("layer alpha should be applied to the global alpha without framebuffers, but %f", alpha)
+	}
+	ctx.EndLayer()
+	if alpha := ctx.getState().alpha; alpha != 1.0 {
+		t.Errorf("EndLayer() should restore the global alpha, but %f", alpha)
+	}
+}
+
+func TestLayerFramebufferReuse(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginLayer(0, 0, 64, 64, 1.0)
+	drawOverlappedRects(ctx)
+	ctx.EndLayer()
+	ctx.EndFrame()
+	if len(ctx.layerPool) != 1 {
+		t.Fatalf("the framebuffer of the layer should be kept, but %d", len(ctx.layerPool))
+	}
+	fb := ctx.layerPool[0]
+
+	for i := range dst.Pix {
+		dst.Pix[i] = 0
+	}
+	ctx.BeginFrame(64, 64, 1.0)
+	ctx.BeginLayer(0, 0, 64, 64, 1.0)
+	ctx.SetFillColor(blue)
+	ctx.BeginPath()
+	ctx.Rect(40, 40, 20, 20)
+	ctx.Fill()
+	ctx.EndLayer()
+	ctx.EndFrame()
+	if len(ctx.layerPool) != 1 || ctx.layerPool[0] != fb {
+		t.Error("the next layer should reuse the framebuffer")
+	}
+	// The reused framebuffer doesn't have the previous content.
+	checkPixel(t, dst, 5, 5, transparent)
+	checkPixel(t, dst, 50, 50, blue)
+
+	ctx.BeginFrame(32, 32, 1.0)
+	ctx.BeginLayer(0, 0, 32, 32, 1.0)
+	if ctx.framebuffer == fb {
+		t.Error("the framebuffer of the different size should not be reused")
+	}
+	if w, h := ctx.framebuffer.Size(); w != 32 || h != 32 {
+		t.Errorf("the framebuffer should have the window size, but %dx%d", w, h)
+	}
+	ctx.EndLayer()
+	ctx.EndFrame()
+	if len(ctx.layerPool) != 1 || ctx.layerPool[0] == fb {
+		t.Error("the framebuffer of the previous size should be deleted")
+	}
+}
+
+func TestCancelFrameLayer(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	ctx.BeginLayer(0, 0, 64, 64, 1.0)
+	drawOverlappedRects(ctx)
+	// Open layers are discarded by CancelFrame().
+	ctx.CancelFrame()
+	if len(ctx.layers) != 0 || ctx.framebuffer != nil {
+		t.Error("CancelFrame() should discard the layers and bind the screen")
+	}
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 5, 5, transparent)
+}
@@ -0,0 +1,21 @@
+package nanovgo
+
+// SetMask sets the image that modulates the alpha of following drawing. The image is stretched over the whole window,
+// so the images of framebuffers in the window size can be used as they are.
+// The alpha channel of RGBA images and the values of alpha images are used.
+// The mask is a part of the render state, so Restore() brings back the previous mask.
+// Pass 0 to disable the mask like ResetMask().
+func (c *Context) SetMask(image int) {
+	if c.recorder != nil {
+		defer c.record(OpSetMask, float32(image))()
+	}
+	c.getState().mask = image
+}
+
+// ResetMask resets and disables the alpha mask.
+func (c *Context) ResetMask() {
+	if c.recorder != nil {
+		defer c.record(OpResetMask)()
+	}
+	c.getState().mask = 0
+}
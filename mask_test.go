@@ -0,0 +1,74 @@
+package nanovgo
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// newTestMask makes the mask that is opaque in the left half, half transparent in the next quarter,
+// and transparent in the rest.
+func newTestMask(w, h int) *image.RGBA {
+	mask := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			switch {
+			case x < w/2:
+				mask.SetRGBA(x, y, color.RGBA{A: 255})
+			case x < w*3/4:
+				mask.SetRGBA(x, y, color.RGBA{A: 128})
+			}
+		}
+	}
+	return mask
+}
+
+func TestMask(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 64, 64, AntiAlias)
+	mask := ctx.CreateImage(newTestMask(64, 64))
+	ctx.Save()
+	ctx.SetMask(mask)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 64, 32)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.Restore()
+
+	// Restore() brings back the state without the mask.
+	ctx.BeginPath()
+	ctx.Rect(0, 32, 64, 32)
+	ctx.SetFillColor(blue)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 10, 10, red)
+	checkPixel(t, dst, 40, 10, color.RGBA{R: 128, A: 128})
+	checkPixel(t, dst, 56, 10, transparent)
+	checkPixel(t, dst, 56, 50, blue)
+
+	ctx.BeginFrame(64, 64, 1.0)
+	ctx.SetMask(mask)
+	ctx.ResetMask()
+	if ctx.getState().mask != 0 {
+		t.Error("ResetMask() should disable the mask")
+	}
+}
+
+func TestSVGContextMask(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.SetMask(ctx.CreateImage(newTestMask(4, 4)))
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.Fill()
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 10, 10)
+		ctx.Fill()
+	})
+	if !strings.Contains(svg, `<mask id="mask1" maskUnits="userSpaceOnUse" x="0" y="0" width="100" height="100" style="mask-type:alpha">`) {
+		t.Errorf("SVG should contain the mask:\n%s", svg)
+	}
+	if count := strings.Count(svg, `<g mask="url(#mask1)">`); count != 2 {
+		t.Errorf("the mask should be shared by 2 paths, but %d:\n%s", count, svg)
+	}
+}
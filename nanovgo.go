@@ -10,7 +10,7 @@ import (
 )
 
 type Context struct {
-	gl             *glContext
+	renderer       Renderer
 	commands       []float32
 	commandX       float32
 	commandY       float32
@@ -27,18 +27,52 @@ type Context struct {
 	fillTriCount   int
 	strokeTriCount int
 	textTriCount   int
+	recorder       *Recorder
+	framebuffer    *Framebuffer
+	layers         []nvgLayer
+	layerPool      []*Framebuffer
+	windowWidth    int
+	windowHeight   int
+}
+
+// NewContextWithRenderer makes new NanoVGo context that draws by the renderer.
+func NewContextWithRenderer(renderer Renderer) (*Context, error) {
+	context := &Context{
+		renderer:   renderer,
+		states:     make([]nvgState, 0, nvgMaxStates),
+		fontImages: make([]int, nvgMaxFontImages),
+		commands:   make([]float32, 0, nvgInitCommandsSize),
+		cache: nvgPathCache{
+			points:   make([]nvgPoint, 0, nvgInitPointsSize),
+			paths:    make([]Path, 0, nvgInitPathsSize),
+			vertexes: make([]Vertex, 0, nvgInitVertsSize),
+		},
+	}
+	context.clearState()
+	context.setDevicePixelRatio(1.0)
+	if err := renderer.Create(); err != nil {
+		return nil, err
+	}
+
+	context.fs = fontstashmini.New(nvgInitFontImageSize, nvgInitFontImageSize)
+
+	context.fontImages[0] = renderer.CreateTexture(TextureAlpha, nvgInitFontImageSize, nvgInitFontImageSize, nil)
+	context.fontImageIdx = 0
+
+	return context, nil
 }
 
 // Delete is called when tearing down NanoVGo context
 func (c *Context) Delete() {
 	for i, fontImage := range c.fontImages {
 		if fontImage != 0 {
-			c.DeleteImage(fontImage)
+			c.renderer.DeleteTexture(fontImage)
 			c.fontImages[i] = 0
 		}
 	}
-	c.gl.renderDelete()
-	c.gl = nil
+	c.deleteLayerFramebuffers()
+	c.renderer.Delete()
+	c.renderer = nil
 }
 
 // BeginFrame begins drawing a new frame
@@ -50,12 +84,12 @@ func (c *Context) Delete() {
 // frame buffer size. In that case you would set windowWidth/Height to the window size
 // devicePixelRatio to: frameBufferWidth / windowWidth.
 func (c *Context) BeginFrame(windowWidth, windowHeight int, devicePixelRatio float32) {
-	c.states = c.states[:0]
-	c.Save()
-	c.getState().reset()
+	c.clearState()
 
 	c.setDevicePixelRatio(devicePixelRatio)
-	c.gl.renderViewport(windowWidth, windowHeight)
+	c.renderer.Viewport(windowWidth, windowHeight)
+	c.windowWidth = windowWidth
+	c.windowHeight = windowHeight
 
 	c.drawCallCount = 0
 	c.fillTriCount = 0
@@ -63,9 +97,28 @@ func (c *Context) BeginFrame(windowWidth, windowHeight int, devicePixelRatio flo
 	c.textTriCount = 0
 }
 
+// CancelFrame cancels drawing the current frame. The queued draw calls are discarded, and the layers that are
+// not ended yet are discarded without being composited.
+func (c *Context) CancelFrame() {
+	for len(c.layers) > 0 {
+		layer := c.layers[len(c.layers)-1]
+		c.layers = c.layers[:len(c.layers)-1]
+		c.Restore()
+		if layer.fb != nil {
+			c.renderer.Cancel()
+			c.BindFramebuffer(layer.parent)
+			c.releaseLayerFramebuffer(layer.fb)
+		}
+	}
+	c.renderer.Cancel()
+}
+
 // EndFrame ends drawing flushing remaining render state.
 func (c *Context) EndFrame() {
-	c.gl.renderFlush()
+	for len(c.layers) > 0 {
+		c.EndLayer()
+	}
+	c.renderer.Flush()
 	if c.fontImageIdx != 0 {
 		fontImage := c.fontImages[c.fontImageIdx]
 		if fontImage == 0 {
@@ -76,7 +129,7 @@ func (c *Context) EndFrame() {
 		for i := 0; i < c.fontImageIdx; i++ {
 			nw, nh, _ := c.ImageSize(c.fontImages[i])
 			if nw < iw || nh < ih {
-				c.DeleteImage(c.fontImages[i])
+				c.renderer.DeleteTexture(c.fontImages[i])
 			} else {
 				c.fontImages[j] = c.fontImages[i]
 				j++
@@ -97,6 +150,9 @@ func (c *Context) EndFrame() {
 // Save pushes and saves the current render state into a state stack.
 // A matching Restore() must be used to restore the state.
 func (c *Context) Save() {
+	if c.recorder != nil {
+		defer c.record(OpSave)()
+	}
 	if len(c.states) >= nvgMaxStates {
 		return
 	}
@@ -109,12 +165,37 @@ func (c *Context) Save() {
 
 // Restore pops and restores current render state.
 func (c *Context) Restore() {
+	if c.recorder != nil {
+		defer c.record(OpRestore)()
+	}
 	nStates := len(c.states)
 	if nStates > 1 {
 		c.states = c.states[:nStates-1]
 	}
 }
 
+// Reset resets current render state to default values. Does not affect the render state stack.
+func (c *Context) Reset() {
+	if c.recorder != nil {
+		defer c.record(OpReset)()
+	}
+	c.getState().reset()
+}
+
+// ClearState drops all saved render states, and leaves only one state that has default values.
+func (c *Context) ClearState() {
+	if c.recorder != nil {
+		defer c.record(OpClearState)()
+	}
+	c.clearState()
+}
+
+func (c *Context) clearState() {
+	c.states = c.states[:0]
+	c.Save()
+	c.Reset()
+}
+
 // Block makes Save/Restore block.
 func (c *Context) Block(block func()) {
 	c.Save()
@@ -123,7 +204,79 @@ func (c *Context) Block(block func()) {
 }
 
 // SetStrokeWidth sets the stroke width of the stroke style.
-func (c *Context) SetStrokeWidth(width float32) { c.getState().strokeWidth = width }
+func (c *Context) SetStrokeWidth(width float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetStrokeWidth, width)()
+	}
+	c.getState().strokeWidth = width
+}
+
+// SetMiterLimit sets the miter limit of the stroke style.
+// Miter limit controls when a sharp corner is beveled.
+func (c *Context) SetMiterLimit(limit float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetMiterLimit, limit)()
+	}
+	c.getState().miterLimit = limit
+}
+
+// SetLineCap sets how the end of the line (cap) is drawn,
+// Can be one of: Butt (default), Round, Square.
+func (c *Context) SetLineCap(cap LineCap) {
+	if c.recorder != nil {
+		defer c.record(OpSetLineCap, float32(cap))()
+	}
+	c.getState().lineCap = cap
+}
+
+// SetLineJoin sets how sharp path corners are drawn.
+// Can be one of Miter (default), Round, Bevel.
+func (c *Context) SetLineJoin(joint LineCap) {
+	if c.recorder != nil {
+		defer c.record(OpSetLineJoin, float32(joint))()
+	}
+	c.getState().lineJoin = joint
+}
+
+// SetLineDash sets the lengths of the dashes and the gaps of the stroke style in turn.
+// If the number of the lengths is odd, they are repeated to make it even. An empty slice draws solid lines.
+// Slices that have negative lengths are ignored. The pattern continues over the corners and the sub-paths.
+func (c *Context) SetLineDash(segments []float32) {
+	for _, segment := range segments {
+		if segment < 0 || segment != segment {
+			return
+		}
+	}
+	var dash []float32
+	if len(segments) > 0 {
+		dash = append(dash, segments...)
+		if len(dash)%2 == 1 {
+			dash = append(dash, segments...)
+		}
+	}
+	if c.recorder != nil {
+		defer c.record(OpSetLineDash, dash...)()
+	}
+	c.getState().lineDash = dash
+}
+
+// SetLineDashOffset sets how far the dash pattern is shifted at the start of the stroke.
+func (c *Context) SetLineDashOffset(offset float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetLineDashOffset, offset)()
+	}
+	c.getState().dashOffset = offset
+}
+
+// SetGlobalAlpha sets the transparency applied to all rendered shapes.
+// Already transparent paths will get proportionally more transparent as well.
+// The alpha is clamped to the range of [0, 1].
+func (c *Context) SetGlobalAlpha(alpha float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetGlobalAlpha, alpha)()
+	}
+	c.getState().alpha = clampF(alpha, 0.0, 1.0)
+}
 
 // SetTransformByValue premultiplies current coordinate system by specified matrix.
 // The parameters are interpreted as matrix as follows:
@@ -131,6 +284,9 @@ func (c *Context) SetStrokeWidth(width float32) { c.getState().strokeWidth = wid
 //   [b d f]
 //   [0 0 1]
 func (c *Context) SetTransformByValue(a, b, cc, d, e, f float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetTransformByValue, a, b, cc, d, e, f)()
+	}
 	t := TransformMatrix{a, b, cc, d, e, f}
 	state := c.getState()
 	state.xform = state.xform.PreMultiply(t)
@@ -138,16 +294,58 @@ func (c *Context) SetTransformByValue(a, b, cc, d, e, f float32) {
 
 // ResetTransform resets current transform to a identity matrix.
 func (c *Context) ResetTransform() {
+	if c.recorder != nil {
+		defer c.record(OpResetTransform)()
+	}
 	state := c.getState()
 	state.xform = IdentityMatrix()
 }
 
 // Translate translates current coordinate system.
 func (c *Context) Translate(x, y float32) {
+	if c.recorder != nil {
+		defer c.record(OpTranslate, x, y)()
+	}
 	state := c.getState()
 	state.xform = state.xform.PreMultiply(TranslateMatrix(x, y))
 }
 
+// Rotate rotates current coordinate system. Angle is specified in radians.
+func (c *Context) Rotate(angle float32) {
+	if c.recorder != nil {
+		defer c.record(OpRotate, angle)()
+	}
+	state := c.getState()
+	state.xform = state.xform.PreMultiply(RotateMatrix(angle))
+}
+
+// SkewX skews the current coordinate system along X axis. Angle is specified in radians.
+func (c *Context) SkewX(angle float32) {
+	if c.recorder != nil {
+		defer c.record(OpSkewX, angle)()
+	}
+	state := c.getState()
+	state.xform = state.xform.PreMultiply(SkewXMatrix(angle))
+}
+
+// SkewY skews the current coordinate system along Y axis. Angle is specified in radians.
+func (c *Context) SkewY(angle float32) {
+	if c.recorder != nil {
+		defer c.record(OpSkewY, angle)()
+	}
+	state := c.getState()
+	state.xform = state.xform.PreMultiply(SkewYMatrix(angle))
+}
+
+// Scale scales the current coordinate system.
+func (c *Context) Scale(x, y float32) {
+	if c.recorder != nil {
+		defer c.record(OpScale, x, y)()
+	}
+	state := c.getState()
+	state.xform = state.xform.PreMultiply(ScaleMatrix(x, y))
+}
+
 // CurrentTransform returns the top part (a-f) of the current transformation matrix.
 //   [a c e]
 //   [b d f]
@@ -159,16 +357,43 @@ func (c *Context) CurrentTransform() TransformMatrix {
 
 // SetStrokeColor sets current stroke style to a solid color.
 func (c *Context) SetStrokeColor(color color.Color) {
+	if c.recorder != nil {
+		defer c.record(OpSetStrokeColor, colorToArgs(color)...)()
+	}
 	c.getState().stroke.setPaintColor(color)
 }
 
 // SetFillColor sets current fill style to a solid color.
 func (c *Context) SetFillColor(color color.Color) {
+	if c.recorder != nil {
+		defer c.record(OpSetFillColor, colorToArgs(color)...)()
+	}
 	c.getState().fill.setPaintColor(color)
 }
 
-func (c *Context) SetFillImage() {
-	//c.getState().fill.image =
+// SetStrokePaint sets current stroke style to a paint, which can be a one of the gradients or a pattern.
+func (c *Context) SetStrokePaint(paint Paint) {
+	if c.recorder != nil {
+		defer c.record(OpSetStrokePaint, paintToArgs(&paint)...)()
+	}
+	state := c.getState()
+	state.stroke = paint
+	state.stroke.Xform = state.stroke.Xform.Multiply(state.xform)
+}
+
+// SetFillPaint sets current fill style to a paint, which can be a one of the gradients or a pattern.
+func (c *Context) SetFillPaint(paint Paint) {
+	if c.recorder != nil {
+		defer c.record(OpSetFillPaint, paintToArgs(&paint)...)()
+	}
+	state := c.getState()
+	state.fill = paint
+	state.fill.Xform = state.fill.Xform.Multiply(state.xform)
+}
+
+// SetFillImage sets current fill style to an image pattern, see ImagePattern() for the parameters.
+func (c *Context) SetFillImage(ox, oy, w, h, angle float32, image int, alpha float32) {
+	c.SetFillPaint(ImagePattern(ox, oy, w, h, angle, image, alpha))
 }
 
 // CreateImageFromGoImage creates image by loading it from the specified image.Image object.
@@ -186,29 +411,39 @@ func (c *Context) CreateImage(img image.Image) int {
 		rgba = image.NewRGBA(bounds)
 		draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
 	}
-	return c.gl.renderCreateTexture(nvgTextureRGBA, size.X, size.Y, rgba.Pix)
+	handle := c.renderer.CreateTexture(TextureRGBA, size.X, size.Y, rgba.Pix)
+	if c.recorder != nil && c.recorder.depth == 0 {
+		c.recorder.list = append(c.recorder.list, DrawOp{Kind: OpCreateImage, Args: []float32{float32(handle)}, Image: imageToRGBA(img)})
+	}
+	return handle
 }
 
 // ImageSize returns the dimensions of a created image.
 func (c *Context) ImageSize(img int) (int, int, error) {
-	return c.gl.renderGetTextureSize(img)
+	return c.renderer.GetTextureSize(img)
 }
 
 // DeleteImage deletes created image.
 func (c *Context) DeleteImage(img int) {
-	c.gl.renderDeleteTexture(img)
+	if c.recorder != nil {
+		defer c.record(OpDeleteImage, float32(img))()
+	}
+	c.renderer.DeleteTexture(img)
 }
 
 // Scissor sets the current scissor rectangle.
 // The scissor rectangle is transformed by the current transform.
 func (c *Context) Scissor(x, y, w, h float32) {
+	if c.recorder != nil {
+		defer c.record(OpScissor, x, y, w, h)()
+	}
 	state := c.getState()
 
 	w = maxF(0.0, w)
 	h = maxF(0.0, h)
 
-	state.scissor.xform = TranslateMatrix(x+w*0.5, y+h*0.5).Multiply(state.xform)
-	state.scissor.extent = [2]float32{w * 0.5, h * 0.5}
+	state.scissor.Xform = TranslateMatrix(x+w*0.5, y+h*0.5).Multiply(state.xform)
+	state.scissor.Extent = [2]float32{w * 0.5, h * 0.5}
 }
 
 // IntersectScissor calculates intersects current scissor rectangle with the specified rectangle.
@@ -218,16 +453,19 @@ func (c *Context) Scissor(x, y, w, h float32) {
 // rectangle and the previous scissor rectangle transformed in the current
 // transform space. The resulting shape is always rectangle.
 func (c *Context) IntersectScissor(x, y, w, h float32) {
+	if c.recorder != nil {
+		defer c.record(OpIntersectScissor, x, y, w, h)()
+	}
 	state := c.getState()
 
-	if state.scissor.extent[0] < 0 {
+	if state.scissor.Extent[0] < 0 {
 		c.Scissor(x, y, w, h)
 		return
 	}
 
-	pXform := state.scissor.xform.Multiply(state.xform.Inverse())
-	ex := state.scissor.extent[0]
-	ey := state.scissor.extent[1]
+	pXform := state.scissor.Xform.Multiply(state.xform.Inverse())
+	ex := state.scissor.Extent[0]
+	ey := state.scissor.Extent[1]
 
 	teX := ex * absF(pXform[0]) * ey * absF(pXform[2])
 	teY := ex * absF(pXform[1]) * ey * absF(pXform[3])
@@ -237,20 +475,194 @@ func (c *Context) IntersectScissor(x, y, w, h float32) {
 
 // ResetScissor resets and disables scissoring.
 func (c *Context) ResetScissor() {
+	if c.recorder != nil {
+		defer c.record(OpResetScissor)()
+	}
 	state := c.getState()
 
-	state.scissor.xform = TransformMatrix{0, 0, 0, 0, 0, 0}
-	state.scissor.extent = [2]float32{-1.0, -1.0}
+	state.scissor.Xform = TransformMatrix{0, 0, 0, 0, 0, 0}
+	state.scissor.Extent = [2]float32{-1.0, -1.0}
 }
 
 // BeginPath clears the current path and sub-paths.
 func (c *Context) BeginPath() {
+	if c.recorder != nil {
+		defer c.record(OpBeginPath)()
+	}
 	c.commands = c.commands[:0]
 	c.cache.clearPathCache()
 }
 
+// MoveTo starts new sub-path with specified point as first point.
+func (c *Context) MoveTo(x, y float32) {
+	if c.recorder != nil {
+		defer c.record(OpMoveTo, x, y)()
+	}
+	c.appendCommand([]float32{float32(nvgMOVETO), x, y})
+}
+
+// LineTo adds line segment from the last point in the path to the specified point.
+func (c *Context) LineTo(x, y float32) {
+	if c.recorder != nil {
+		defer c.record(OpLineTo, x, y)()
+	}
+	c.appendCommand([]float32{float32(nvgLINETO), x, y})
+}
+
+// BezierTo adds cubic bezier segment from last point in the path via two control points to the specified point.
+func (c *Context) BezierTo(c1x, c1y, c2x, c2y, x, y float32) {
+	if c.recorder != nil {
+		defer c.record(OpBezierTo, c1x, c1y, c2x, c2y, x, y)()
+	}
+	c.appendCommand([]float32{float32(nvgBEZIERTO), c1x, c1y, c2x, c2y, x, y})
+}
+
+// QuadTo adds quadratic bezier segment from last point in the path via a control point to the specified point.
+func (c *Context) QuadTo(cx, cy, x, y float32) {
+	if c.recorder != nil {
+		defer c.record(OpQuadTo, cx, cy, x, y)()
+	}
+	x0 := c.commandX
+	y0 := c.commandY
+	c.appendCommand([]float32{
+		float32(nvgBEZIERTO),
+		x0 + 2.0/3.0*(cx-x0), y0 + 2.0/3.0*(cy-y0),
+		x + 2.0/3.0*(cx-x), y + 2.0/3.0*(cy-y),
+		x, y,
+	})
+}
+
+// ArcTo adds an arc segment at the corner defined by the last path point, and two specified points.
+func (c *Context) ArcTo(x1, y1, x2, y2, radius float32) {
+	if c.recorder != nil {
+		defer c.record(OpArcTo, x1, y1, x2, y2, radius)()
+	}
+	if len(c.commands) == 0 {
+		return
+	}
+	x0 := c.commandX
+	y0 := c.commandY
+
+	// Handle degenerate cases.
+	if ptEquals(x0, y0, x1, y1, c.distTol) ||
+		ptEquals(x1, y1, x2, y2, c.distTol) ||
+		distPtSeg(x1, y1, x0, y0, x2, y2) < c.distTol*c.distTol ||
+		radius < c.distTol {
+		c.LineTo(x1, y1)
+		return
+	}
+
+	// Calculate tangential circle to lines (x0,y0)-(x1,y1) and (x1,y1)-(x2,y2).
+	_, dx0, dy0 := normalize(x0-x1, y0-y1)
+	_, dx1, dy1 := normalize(x2-x1, y2-y1)
+	a := acosF(dx0*dx1 + dy0*dy1)
+	d := radius / tanF(a/2.0)
+
+	if d > 10000.0 {
+		c.LineTo(x1, y1)
+		return
+	}
+
+	var cx, cy, a0, a1 float32
+	var dir Direction
+	if cross(dx0, dy0, dx1, dy1) > 0.0 {
+		cx = x1 + dx0*d + dy0*radius
+		cy = y1 + dy0*d + -dx0*radius
+		a0 = atan2F(dx0, -dy0)
+		a1 = atan2F(-dx1, dy1)
+		dir = Clockwise
+	} else {
+		cx = x1 + dx0*d + -dy0*radius
+		cy = y1 + dy0*d + dx0*radius
+		a0 = atan2F(-dx0, dy0)
+		a1 = atan2F(dx1, -dy1)
+		dir = CounterClockwise
+	}
+	c.Arc(cx, cy, radius, a0, a1, dir)
+}
+
+// Arc creates new circle arc shaped sub-path. The arc center is at cx,cy, the arc radius is r,
+// and the arc is drawn from angle a0 to a1, and swept in direction dir (CounterClockwise or Clockwise).
+// Angles are specified in radians. If r is not positive, the arc is the point at the center.
+func (c *Context) Arc(cx, cy, r, a0, a1 float32, dir Direction) {
+	if c.recorder != nil {
+		defer c.record(OpArc, cx, cy, r, a0, a1, float32(dir))()
+	}
+	var move nvgCommands
+	if len(c.commands) > 0 {
+		move = nvgLINETO
+	} else {
+		move = nvgMOVETO
+	}
+	if r <= 0.0 {
+		// The arc degenerates into its center like the degenerate radius of ArcTo().
+		c.appendCommand([]float32{float32(move), cx, cy})
+		return
+	}
+
+	// Clamp angles
+	da := a1 - a0
+	if dir == Clockwise {
+		if absF(da) >= PI*2 {
+			da = PI * 2
+		} else {
+			for da < 0.0 {
+				da += PI * 2
+			}
+		}
+	} else {
+		if absF(da) >= PI*2 {
+			da = -PI * 2
+		} else {
+			for da > 0.0 {
+				da -= PI * 2
+			}
+		}
+	}
+
+	// Split arc into max 90 degree segments, and add more segments while the
+	// bezier approximation error is larger than the tessellation tolerance.
+	nDivs := maxI(1, minI(int(absF(da)/(PI*0.5)+0.5), 5))
+	scaledR := r * c.getState().xform.getAverageScale()
+	for nDivs < nvgMaxArcDivs && arcBezierError(scaledR, da/float32(nDivs)) > c.tessTol {
+		nDivs++
+	}
+	// The handle length is Kappa90 for 90 degree segments, and shorter for the smaller segments.
+	hda := (da / float32(nDivs)) / 2.0
+	kappa := absF(4.0 / 3.0 * (1.0 - cosF(hda)) / sinF(hda))
+
+	if dir == CounterClockwise {
+		kappa = -kappa
+	}
+
+	values := make([]float32, 0, 3+nDivs*7)
+	var px, py, pTanX, pTanY float32
+	for i := 0; i <= nDivs; i++ {
+		a := a0 + da*(float32(i)/float32(nDivs))
+		dy, dx := sinCosF(a)
+		x := cx + dx*r
+		y := cy + dy*r
+		tanX := -dy * r * kappa
+		tanY := dx * r * kappa
+
+		if i == 0 {
+			values = append(values, float32(move), x, y)
+		} else {
+			values = append(values, float32(nvgBEZIERTO), px+pTanX, py+pTanY, x-tanX, y-tanY, x, y)
+		}
+		px = x
+		py = y
+		pTanX = tanX
+		pTanY = tanY
+	}
+	c.appendCommand(values)
+}
+
 // Rect creates new rectangle shaped sub-path.
 func (c *Context) Rect(x, y, w, h float32) {
+	if c.recorder != nil {
+		defer c.record(OpRect, x, y, w, h)()
+	}
 	c.appendCommand([]float32{
 		float32(nvgMOVETO), x, y,
 		float32(nvgLINETO), x, y + h,
@@ -262,6 +674,9 @@ func (c *Context) Rect(x, y, w, h float32) {
 
 // RoundedRect creates new rounded rectangle shaped sub-path.
 func (c *Context) RoundedRect(x, y, w, h, r float32) {
+	if c.recorder != nil {
+		defer c.record(OpRoundedRect, x, y, w, h, r)()
+	}
 	if r < 0.1 {
 		c.Rect(x, y, w, h)
 	} else {
@@ -284,6 +699,9 @@ func (c *Context) RoundedRect(x, y, w, h, r float32) {
 
 // Ellipse creates new ellipse shaped sub-path.
 func (c *Context) Ellipse(cx, cy, rx, ry float32) {
+	if c.recorder != nil {
+		defer c.record(OpEllipse, cx, cy, rx, ry)()
+	}
 	c.appendCommand([]float32{
 		float32(nvgMOVETO), cx - rx, cy,
 		float32(nvgBEZIERTO), cx - rx, cy + ry*Kappa90, cx - rx*Kappa90, cy + ry, cx, cy + ry,
@@ -296,16 +714,25 @@ func (c *Context) Ellipse(cx, cy, rx, ry float32) {
 
 // Circle creates new circle shaped sub-path.
 func (c *Context) Circle(cx, cy, r float32) {
+	if c.recorder != nil {
+		defer c.record(OpCircle, cx, cy, r)()
+	}
 	c.Ellipse(cx, cy, r, r)
 }
 
 // ClosePath closes current sub-path with a line segment.
 func (c *Context) ClosePath() {
+	if c.recorder != nil {
+		defer c.record(OpClosePath)()
+	}
 	c.appendCommand([]float32{float32(nvgCLOSE)})
 }
 
 // PathWinding sets the current sub-path winding, see Winding.
 func (c *Context) PathWinding(winding Winding) {
+	if c.recorder != nil {
+		defer c.record(OpPathWinding, float32(winding))()
+	}
 	c.appendCommand([]float32{float32(nvgWINDING), float32(winding)})
 }
 
@@ -315,16 +742,16 @@ func (c *Context) DebugDumpPathCache() {
 	for i := 0; i < len(c.cache.paths); i++ {
 		path := &c.cache.paths[i]
 		log.Printf(" - Path %d\n", i)
-		if len(path.fills) > 0 {
-			log.Printf("   - fill: %d\n", len(path.fills))
-			for _, fill := range path.fills {
-				log.Printf("%f\t%f\n", fill.x, fill.y)
+		if len(path.Fills) > 0 {
+			log.Printf("   - fill: %d\n", len(path.Fills))
+			for _, fill := range path.Fills {
+				log.Printf("%f\t%f\n", fill.X, fill.Y)
 			}
 		}
-		if len(path.strokes) > 0 {
-			log.Printf("   - strokes: %d\n", len(path.strokes))
-			for _, stroke := range path.strokes {
-				log.Printf("%f\t%f\n", stroke.x, stroke.y)
+		if len(path.Strokes) > 0 {
+			log.Printf("   - strokes: %d\n", len(path.Strokes))
+			for _, stroke := range path.Strokes {
+				log.Printf("%f\t%f\n", stroke.X, stroke.Y)
 			}
 		}
 	}
@@ -332,62 +759,107 @@ func (c *Context) DebugDumpPathCache() {
 
 // Fill fills the current path with current fill style.
 func (c *Context) Fill() {
+	if c.recorder != nil {
+		defer c.record(OpFill)()
+	}
 	state := c.getState()
 	fillPaint := state.fill
 	c.flattenPaths()
+	c.applyRenderState()
+
+	// Apply global alpha
+	fillPaint.multiplyAlpha(state.alpha)
+
+	if vector, ok := c.renderer.(vectorRenderer); ok {
+		vector.fillPath(&fillPaint, &state.scissor, c.commands)
+		c.drawCallCount++
+		return
+	}
 
-	if c.gl.edgeAntiAlias() {
+	if c.renderer.EdgeAntiAlias() {
 		c.cache.expandFill(c.fringeWidth, Miter, 2.4, c.fringeWidth)
 	} else {
 		c.cache.expandFill(0.0, Miter, 2.4, c.fringeWidth)
 	}
+	// Self-intersecting paths can turn to the same side at all the points, so they are filled by the stencil.
+	if state.fillRule == EvenOdd {
+		for i := range c.cache.paths {
+			c.cache.paths[i].Convex = false
+		}
+	}
 
-	c.gl.renderFill(&fillPaint, &state.scissor, c.fringeWidth, c.cache.bounds, c.cache.paths)
+	c.renderer.Fill(&fillPaint, &state.scissor, c.fringeWidth, c.cache.bounds, c.cache.paths)
 
 	// Count triangles
 	for i := 0; i < len(c.cache.paths); i++ {
 		path := &c.cache.paths[i]
-		c.fillTriCount += len(path.fills) - 2
-		c.strokeTriCount += len(path.strokes) - 2
+		c.fillTriCount += len(path.Fills) - 2
+		c.strokeTriCount += len(path.Strokes) - 2
 		c.drawCallCount += 2
 	}
 }
 
 // Stroke draws the current path with current stroke style.
 func (c *Context) Stroke() {
+	if c.recorder != nil {
+		defer c.record(OpStroke)()
+	}
 	state := c.getState()
 	scale := state.xform.getAverageScale()
 	strokeWidth := clampF(state.strokeWidth*scale, 0.0, 200.0)
 	strokePaint := state.stroke
+	c.applyRenderState()
+
+	if vector, ok := c.renderer.(vectorRenderer); ok {
+		strokePaint.multiplyAlpha(state.alpha)
+		vector.strokePath(&strokePaint, &state.scissor, &nvgStrokeStyle{
+			width:      state.strokeWidth * scale,
+			lineCap:    state.lineCap,
+			lineJoin:   state.lineJoin,
+			miterLimit: state.miterLimit,
+			lineDash:   scaleDash(state.lineDash, scale),
+			dashOffset: state.dashOffset * scale,
+		}, c.commands)
+		c.drawCallCount++
+		return
+	}
 
 	if strokeWidth < c.fringeWidth {
 		// If the stroke width is less than pixel size, use alpha to emulate coverage.
+		// Since coverage is area, scale by alpha*alpha.
+		alpha := clampF(strokeWidth/c.fringeWidth, 0.0, 1.0)
+		strokePaint.multiplyAlpha(alpha * alpha)
 		strokeWidth = c.fringeWidth
 	}
 
+	// Apply global alpha
+	strokePaint.multiplyAlpha(state.alpha)
+
 	c.flattenPaths()
-	for _, path := range c.cache.paths {
-		if path.count == 1 {
-			panic("")
-		}
+
+	dashed := len(state.lineDash) > 0
+	if dashed {
+		c.cache.dashPaths(scaleDash(state.lineDash, scale), state.dashOffset*scale, c.distTol)
 	}
-	const miterLimit = 10 // TODO: remove
-	const lineCap = Butt
-	const lineJoin = Miter // or Round
 
-	if c.gl.edgeAntiAlias() {
-		c.cache.expandStroke(strokeWidth*0.5+c.fringeWidth*0.5, lineCap, lineJoin, miterLimit, c.fringeWidth, c.tessTol)
+	if c.renderer.EdgeAntiAlias() {
+		c.cache.expandStroke(strokeWidth*0.5+c.fringeWidth*0.5, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
 	} else {
-		c.cache.expandStroke(strokeWidth*0.5, lineCap, lineJoin, miterLimit, c.fringeWidth, c.tessTol)
+		c.cache.expandStroke(strokeWidth*0.5, state.lineCap, state.lineJoin, state.miterLimit, c.fringeWidth, c.tessTol)
 	}
-	c.gl.renderStroke(&strokePaint, &state.scissor, c.fringeWidth, strokeWidth, c.cache.paths)
+	c.renderer.Stroke(&strokePaint, &state.scissor, c.fringeWidth, strokeWidth, c.cache.paths)
 
 	// Count triangles
 	for i := 0; i < len(c.cache.paths); i++ {
 		path := &c.cache.paths[i]
-		c.strokeTriCount += len(path.strokes) - 2
+		c.strokeTriCount += len(path.Strokes) - 2
 		c.drawCallCount += 2
 	}
+
+	// The dashes replace the flattened paths, so following Fill() flattens the paths again.
+	if dashed {
+		c.cache.clearPathCache()
+	}
 }
 
 // CreateFont creates font by loading it from the disk from specified file name.
@@ -408,24 +880,52 @@ func (c *Context) FindFont(name string) int {
 }
 
 // SetFontSize sets the font size of current text style.
-func (c *Context) SetFontSize(size float32) { c.getState().fontSize = size }
+func (c *Context) SetFontSize(size float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetFontSize, size)()
+	}
+	c.getState().fontSize = size
+}
 
 // SetTextLetterSpacing sets the letter spacing of current text style.
-func (c *Context) SetTextLetterSpacing(spacing float32) { c.getState().letterSpacing = spacing }
+func (c *Context) SetTextLetterSpacing(spacing float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetTextLetterSpacing, spacing)()
+	}
+	c.getState().letterSpacing = spacing
+}
 
 // SetTextLineHeight sets the line height of current text style.
 func (c *Context) SetTextLineHeight(lineHeight float32) {
+	if c.recorder != nil {
+		defer c.record(OpSetTextLineHeight, lineHeight)()
+	}
 	c.getState().lineHeight = lineHeight
 }
 
 // SetTextAlign sets the text align of current text style.
-func (c *Context) SetTextAlign(align Align) { c.getState().textAlign = align }
+func (c *Context) SetTextAlign(align Align) {
+	if c.recorder != nil {
+		defer c.record(OpSetTextAlign, float32(align))()
+	}
+	c.getState().textAlign = align
+}
 
 // SetFontFaceID sets the font face based on specified id of current text style.
-func (c *Context) SetFontFaceID(font int) { c.getState().fontID = font }
+func (c *Context) SetFontFaceID(font int) {
+	if c.recorder != nil {
+		defer c.record(OpSetFontFaceID, float32(font))()
+	}
+	c.getState().fontID = font
+}
 
 // SetFontFace sets the font face based on specified name of current text style.
-func (c *Context) SetFontFace(font string) { c.getState().fontID = c.fs.GetFontByName(font) }
+func (c *Context) SetFontFace(font string) {
+	if c.recorder != nil {
+		defer c.recordOp(DrawOp{Kind: OpSetFontFace, Text: font})()
+	}
+	c.getState().fontID = c.fs.GetFontByName(font)
+}
 
 // Text draws text string at specified location. If end is specified only the sub-string up to the end is drawn.
 func (c *Context) Text(x, y float32, str string) float32 {
@@ -434,12 +934,16 @@ func (c *Context) Text(x, y float32, str string) float32 {
 
 // TextRune is an alternate version of Text that accepts rune slice.
 func (c *Context) TextRune(x, y float32, runes []rune) float32 {
+	if c.recorder != nil {
+		defer c.recordOp(DrawOp{Kind: OpText, Args: []float32{x, y}, Text: string(runes)})()
+	}
 	state := c.getState()
 	scale := state.getFontScale() * c.devicePxRatio
 	invScale := 1.0 / scale
 	if state.fontID == fontstashmini.INVALID {
 		return 0
 	}
+	c.applyRenderState()
 
 	c.fs.SetSize(state.fontSize * scale)
 	c.fs.SetSpacing(state.letterSpacing * scale)
@@ -447,6 +951,10 @@ func (c *Context) TextRune(x, y float32, runes []rune) float32 {
 	c.fs.SetAlign(fontstashmini.FONSAlign(state.textAlign))
 	c.fs.SetFont(state.fontID)
 
+	if vector, ok := c.renderer.(vectorRenderer); ok {
+		return c.vectorText(vector, x, y, runes)
+	}
+
 	vertexCount := maxI(2, len(runes)) * 4 // conservative estimate.
 	vertexes := c.cache.allocVertexes(vertexCount)
 
@@ -645,8 +1153,10 @@ func (c *Context) flattenPaths() {
 			area := polyArea(points, path.count)
 			if path.winding == Solid && area < 0.0 {
 				polyReverse(points, path.count)
+				path.flipped = true
 			} else if path.winding == Hole && area > 0.0 {
 				polyReverse(points, path.count)
+				path.flipped = true
 			}
 		}
 		for i := 0; i < path.count; i++ {
@@ -680,7 +1190,7 @@ func (c *Context) flushTextTexture() {
 			y := dirty[1]
 			w := dirty[2] - x
 			h := dirty[3] - y
-			c.gl.renderUpdateTexture(fontImage, x, y, w, h, data)
+			c.renderer.UpdateTexture(fontImage, x, y, w, h, data)
 		}
 	}
 }
@@ -705,21 +1215,55 @@ func (c *Context) allocTextAtlas() bool {
 			iw = nvgMaxFontImageSize
 			ih = nvgMaxFontImageSize
 		}
-		c.fontImages[c.fontImageIdx+1] = c.gl.renderCreateTexture(nvgTextureALPHA, iw, ih, nil)
+		c.fontImages[c.fontImageIdx+1] = c.renderer.CreateTexture(TextureAlpha, iw, ih, nil)
 	}
 	c.fontImageIdx++
 	c.fs.ResetAtlas(iw, ih)
 	return true
 }
 
-func (c *Context) renderText(vertexes []nvgVertex) {
+// vectorText passes the text to vectorRenderer instead of the glyph quads. It is called from TextRune()
+// after the font settings are applied to the font stash.
+func (c *Context) vectorText(vector vectorRenderer, x, y float32, runes []rune) float32 {
+	state := c.getState()
+	scale := state.getFontScale() * c.devicePxRatio
+	invScale := 1.0 / scale
+
+	iter := c.fs.TextIterForRunes(x*scale, y*scale, runes)
+	run := &nvgTextRun{
+		xform:         state.xform,
+		fontID:        state.fontID,
+		fontName:      c.fs.GetFontName(),
+		fontData:      c.fs.GetFontData(),
+		fontSize:      state.fontSize,
+		letterSpacing: state.letterSpacing,
+		x:             iter.X * invScale,
+		y:             iter.Y * invScale,
+		runes:         runes,
+	}
+	for {
+		if _, ok := iter.Next(); !ok {
+			break
+		}
+	}
+	paint := state.fill
+	paint.multiplyAlpha(state.alpha)
+	vector.text(&paint, &state.scissor, run)
+	c.drawCallCount++
+	return iter.X
+}
+
+func (c *Context) renderText(vertexes []Vertex) {
 	state := c.getState()
 	paint := state.fill
 
 	// Render triangles
-	paint.image = c.fontImages[c.fontImageIdx]
+	paint.Image = c.fontImages[c.fontImageIdx]
+
+	// Apply global alpha
+	paint.multiplyAlpha(state.alpha)
 
-	c.gl.renderTriangleStrip(&paint, &state.scissor, vertexes)
+	c.renderer.TriangleStrip(&paint, &state.scissor, vertexes)
 
 	c.drawCallCount++
 	c.textTriCount += len(vertexes) / 3
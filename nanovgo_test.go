@@ -1,6 +1,7 @@
 package nanovgo
 
 import (
+	"image/color"
 	"testing"
 )
 
@@ -71,3 +72,153 @@ func TestStateSaveRestore2(t *testing.T) {
 		t.Errorf("Restore() should set saved xform, but %v", topStateAgain.xform)
 	}
 }
+
+func TestClearState(t *testing.T) {
+	c := Context{}
+	c.ClearState()
+	c.Translate(10, 5)
+	c.Save()
+	c.Save()
+
+	c.ClearState()
+
+	if len(c.states) != 1 {
+		t.Errorf("ClearState() should leave only one state, but %d", len(c.states))
+	}
+	if !equal(c.getState().xform, IdentityMatrix()) {
+		t.Errorf("ClearState() should reset xform, but %v", c.getState().xform)
+	}
+}
+
+func TestSetGlobalAlphaClamp(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 32, 16, AntiAlias)
+	ctx.SetGlobalAlpha(-0.5)
+	if alpha := ctx.getState().alpha; alpha != 0.0 {
+		t.Errorf("negative alpha should be clamped to 0.0, but %f", alpha)
+	}
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 16)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+
+	ctx.SetGlobalAlpha(1.5)
+	if alpha := ctx.getState().alpha; alpha != 1.0 {
+		t.Errorf("alpha bigger than 1.0 should be clamped to 1.0, but %f", alpha)
+	}
+	ctx.BeginPath()
+	ctx.Rect(16, 0, 16, 16)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 8, 8, transparent)
+	checkPixel(t, dst, 24, 8, red)
+}
+
+func TestStrokeStyleSaveRestore(t *testing.T) {
+	c := Context{}
+	c.ClearState()
+	state := c.getState()
+	if state.lineCap != Butt || state.lineJoin != Miter || state.miterLimit != 10.0 {
+		t.Errorf("initial stroke style should be Butt, Miter and 10, but %d, %d and %f", state.lineCap, state.lineJoin, state.miterLimit)
+	}
+	c.SetLineCap(Round)
+	c.SetLineJoin(Bevel)
+	c.SetMiterLimit(4.0)
+
+	c.Save()
+	state = c.getState()
+	if state.lineCap != Round || state.lineJoin != Bevel || state.miterLimit != 4.0 {
+		t.Errorf("stroke style should be same with parent's one, but %d, %d and %f", state.lineCap, state.lineJoin, state.miterLimit)
+	}
+	c.SetLineCap(Square)
+	c.SetLineJoin(Round)
+	c.SetMiterLimit(2.0)
+
+	c.Restore()
+	state = c.getState()
+	if state.lineCap != Round || state.lineJoin != Bevel || state.miterLimit != 4.0 {
+		t.Errorf("Restore() should set saved stroke style, but %d, %d and %f", state.lineCap, state.lineJoin, state.miterLimit)
+	}
+}
+
+func TestLineCap(t *testing.T) {
+	cases := []struct {
+		lineCap        LineCap
+		end, cornerEnd color.RGBA
+	}{
+		{Butt, transparent, transparent},
+		{Square, red, red},
+		{Round, red, transparent},
+	}
+	for _, c := range cases {
+		ctx, dst := newTestImageContext(t, 32, 32, 0)
+		ctx.BeginPath()
+		ctx.MoveTo(8, 16)
+		ctx.LineTo(24, 16)
+		ctx.SetStrokeWidth(8)
+		ctx.SetStrokeColor(red)
+		ctx.SetLineCap(c.lineCap)
+		ctx.Stroke()
+		ctx.EndFrame()
+
+		checkPixel(t, dst, 6, 16, c.end)
+		checkPixel(t, dst, 5, 12, c.cornerEnd)
+	}
+}
+
+func TestLineJoin(t *testing.T) {
+	cases := []struct {
+		lineJoin      LineCap
+		inner, corner color.RGBA
+	}{
+		{Miter, red, red},
+		{Round, red, transparent},
+		{Bevel, transparent, transparent},
+	}
+	for _, c := range cases {
+		ctx, dst := newTestImageContext(t, 32, 32, 0)
+		ctx.BeginPath()
+		ctx.MoveTo(4, 16)
+		ctx.LineTo(16, 16)
+		ctx.LineTo(16, 4)
+		ctx.SetStrokeWidth(8)
+		ctx.SetStrokeColor(red)
+		ctx.SetLineJoin(c.lineJoin)
+		ctx.Stroke()
+		ctx.EndFrame()
+
+		checkPixel(t, dst, 18, 18, c.inner)
+		checkPixel(t, dst, 19, 19, c.corner)
+	}
+
+	// The sharp corner is beveled when the miter is longer than the limit.
+	ctx, dst := newTestImageContext(t, 32, 32, 0)
+	ctx.BeginPath()
+	ctx.MoveTo(4, 16)
+	ctx.LineTo(16, 16)
+	ctx.LineTo(16, 4)
+	ctx.SetStrokeWidth(8)
+	ctx.SetStrokeColor(red)
+	ctx.SetMiterLimit(1.0)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 19, 19, transparent)
+}
+
+func TestButtCapEndFringe(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 32, 32, AntiAlias)
+	ctx.BeginPath()
+	ctx.MoveTo(16, 4)
+	ctx.LineTo(16, 24.3)
+	ctx.SetStrokeWidth(8)
+	ctx.SetStrokeColor(red)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	// The fringe at the end covers the row partially on both sides of the line.
+	left, right := dst.RGBAAt(13, 24), dst.RGBAAt(18, 24)
+	if left.A == 0 || left.A == 255 || absF(float32(left.A)-float32(right.A)) > 2 {
+		t.Errorf("the fringe at the end should be symmetric, but %v and %v", left, right)
+	}
+}
@@ -2,14 +2,278 @@ package nanovgo
 
 import (
 	"image/color"
+	"math"
+	"sort"
 )
 
+// Paint is used for fill and stroke styles. Gradients and image patterns are created by the functions
+// in this file, and passed to Context.SetFillPaint() or Context.SetStrokePaint().
+// The fields are read by Renderer implementations.
 type Paint struct {
-	color color.Color
-	image int
+	Xform      TransformMatrix
+	Extent     [2]float32
+	Radius     float32
+	Feather    float32
+	InnerColor color.Color
+	OuterColor color.Color
+	Image      int
+	// Stops replaces InnerColor and OuterColor of the gradient if it is not empty.
+	// InnerColor and OuterColor keep the first and the last colors for the renderers that don't support stops.
+	Stops GradientStops
+	// Conic makes the gradient sweep around the origin of Xform. The offsets of the stops are the angles
+	// from the x axis divided by 2*PI.
+	Conic bool
+}
+
+// GradientStop is a color at the offset of the gradient. The offset is in the range of [0, 1].
+type GradientStop struct {
+	Offset float32
+	Color  color.Color
+}
+
+// GradientStops is a list of gradient stops used by LinearGradientStops() and RadialGradientStops().
+// Colors between the stops are interpolated in alpha-premultiplied space. Colors before the first stop and after the
+// last stop are the same as the nearest stop.
+type GradientStops []GradientStop
+
+// sorted returns the copy of the stops that is sorted by the offsets. Offsets are clamped into [0, 1].
+func (s GradientStops) sorted() GradientStops {
+	stops := make(GradientStops, len(s))
+	copy(stops, s)
+	for i := range stops {
+		stops[i].Offset = clampF(stops[i].Offset, 0.0, 1.0)
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].Offset < stops[j].Offset
+	})
+	return stops
+}
+
+// colorAt returns the color at the offset.
+func (s GradientStops) colorAt(offset float32) color.Color {
+	if offset <= s[0].Offset {
+		return s[0].Color
+	}
+	for i := 1; i < len(s); i++ {
+		if offset < s[i].Offset {
+			prev := s[i-1]
+			return mixColor(prev.Color, s[i].Color, (offset-prev.Offset)/(s[i].Offset-prev.Offset))
+		}
+	}
+	return s[len(s)-1].Color
 }
 
 func (p *Paint) setPaintColor(color color.Color) {
-	p.color = color
-	p.image = 0
+	p.Xform = IdentityMatrix()
+	p.Extent = [2]float32{0.0, 0.0}
+	p.Radius = 0.0
+	p.Feather = 1.0
+	p.InnerColor = color
+	p.OuterColor = color
+	p.Image = 0
+	p.Stops = nil
+	p.Conic = false
+}
+
+func (p *Paint) multiplyAlpha(alpha float32) {
+	p.InnerColor = multiplyAlpha(p.InnerColor, alpha)
+	p.OuterColor = multiplyAlpha(p.OuterColor, alpha)
+	if len(p.Stops) > 0 && alpha < 1.0 {
+		stops := make(GradientStops, len(p.Stops))
+		for i, stop := range p.Stops {
+			stops[i] = GradientStop{Offset: stop.Offset, Color: multiplyAlpha(stop.Color, alpha)}
+		}
+		p.Stops = stops
+	}
+}
+
+// gradientStops returns the stops of the gradient. Two-color gradients have the inner color at 0 and the outer color at 1.
+func (p *Paint) gradientStops() GradientStops {
+	if len(p.Stops) > 0 {
+		return p.Stops
+	}
+	return GradientStops{{Offset: 0, Color: p.InnerColor}, {Offset: 1, Color: p.OuterColor}}
+}
+
+// withStops sets the stops to the two-color gradient.
+func (p Paint) withStops(stops GradientStops) Paint {
+	if len(stops) == 0 {
+		return p
+	}
+	p.Stops = stops.sorted()
+	p.InnerColor = p.Stops[0].Color
+	p.OuterColor = p.Stops[len(p.Stops)-1].Color
+	return p
+}
+
+func multiplyAlpha(c color.Color, alpha float32) color.Color {
+	if alpha >= 1.0 {
+		return c
+	}
+	r, g, b, a := c.RGBA()
+	return color.RGBA64{
+		R: uint16(float32(r) * alpha),
+		G: uint16(float32(g) * alpha),
+		B: uint16(float32(b) * alpha),
+		A: uint16(float32(a) * alpha),
+	}
+}
+
+// colorToArray returns alpha-premultiplied color components in the range of [0, 1].
+func colorToArray(c color.Color) [4]float32 {
+	r, g, b, a := c.RGBA()
+	const max = 0xffff
+	return [4]float32{float32(r) / max, float32(g) / max, float32(b) / max, float32(a) / max}
+}
+
+// LinearGradient creates and returns a linear gradient. Parameters (sx,sy)-(ex,ey) specify the start and end coordinates
+// of the linear gradient, iColor specifies the start color and oColor the end color.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func LinearGradient(sx, sy, ex, ey float32, iColor, oColor color.Color) Paint {
+	large := nvgGradientLarge
+	dx := ex - sx
+	dy := ey - sy
+	d := sqrtF(dx*dx + dy*dy)
+	if d > 0.0001 {
+		dx /= d
+		dy /= d
+	} else {
+		dx = 0
+		dy = 1
+	}
+
+	return Paint{
+		Xform:      TransformMatrix{dy, -dx, dx, dy, sx - dx*large, sy - dy*large},
+		Extent:     [2]float32{large, large + d*0.5},
+		Radius:     0.0,
+		Feather:    maxF(1.0, d),
+		InnerColor: iColor,
+		OuterColor: oColor,
+	}
+}
+
+// LinearGradientStops creates and returns a linear gradient that has the color stops. Parameters (sx,sy)-(ex,ey)
+// specify the start and end coordinates of the linear gradient, and the offset 0 and 1 of the stops are at them.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func LinearGradientStops(sx, sy, ex, ey float32, stops GradientStops) Paint {
+	return LinearGradient(sx, sy, ex, ey, color.Transparent, color.Transparent).withStops(stops)
+}
+
+// RadialGradient creates and returns a radial gradient. Parameters (cx,cy) specify the center, inr and outr specify
+// the inner and outer radius of the gradient, iColor specifies the start color and oColor the end color.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func RadialGradient(cx, cy, inr, outr float32, iColor, oColor color.Color) Paint {
+	r := (inr + outr) * 0.5
+	f := outr - inr
+
+	return Paint{
+		Xform:      TranslateMatrix(cx, cy),
+		Extent:     [2]float32{r, r},
+		Radius:     r,
+		Feather:    maxF(1.0, f),
+		InnerColor: iColor,
+		OuterColor: oColor,
+	}
+}
+
+// RadialGradientStops creates and returns a radial gradient that has the color stops. Parameters (cx,cy) specify
+// the center, inr and outr specify the inner and outer radius of the gradient, and the offset 0 and 1 of the stops
+// are at them.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func RadialGradientStops(cx, cy, inr, outr float32, stops GradientStops) Paint {
+	return RadialGradient(cx, cy, inr, outr, color.Transparent, color.Transparent).withStops(stops)
+}
+
+// ConicGradient creates and returns a conic gradient that sweeps the colors of the stops around (cx,cy).
+// The offset 0 is at startAngle (in radians), and the offsets increase clockwise to 1 at the full turn.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func ConicGradient(cx, cy, startAngle float32, stops GradientStops) Paint {
+	xform := RotateMatrix(startAngle)
+	xform[4] = cx
+	xform[5] = cy
+	paint := Paint{
+		Xform:      xform,
+		Feather:    1.0,
+		InnerColor: color.Transparent,
+		OuterColor: color.Transparent,
+		Conic:      true,
+	}
+	return paint.withStops(stops)
+}
+
+// conicOffset returns the offset of the conic gradient at the point in the paint coordinates.
+func conicOffset(x, y float32) float32 {
+	offset := float32(math.Atan2(float64(y), float64(x))) / (2 * PI)
+	if offset < 0 {
+		offset += 1.0
+	}
+	return offset
+}
+
+// BoxGradient creates and returns a box gradient. Box gradient is a feathered rounded rectangle, it is useful for rendering
+// drop shadows or highlights for boxes. Parameters (x,y) define the top-left corner of the rectangle,
+// (w,h) define the size of the rectangle, r defines the corner radius, and f feather. Feather defines how blurry
+// the border of the rectangle is. Parameter iColor specifies the inner color and oColor the outer color of the gradient.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func BoxGradient(x, y, w, h, r, f float32, iColor, oColor color.Color) Paint {
+	return Paint{
+		Xform:      TranslateMatrix(x+w*0.5, y+h*0.5),
+		Extent:     [2]float32{w * 0.5, h * 0.5},
+		Radius:     r,
+		Feather:    maxF(1.0, f),
+		InnerColor: iColor,
+		OuterColor: oColor,
+	}
+}
+
+// BoxGradientStops creates and returns a box gradient that has the color stops. Parameters (x,y) define the top-left
+// corner of the rectangle, (w,h) define the size of the rectangle, r defines the corner radius, and f feather. The offset
+// 0 of the stops is at the inner edge of the feather, and the offset 1 is at the outer edge.
+// The gradient is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func BoxGradientStops(x, y, w, h, r, f float32, stops GradientStops) Paint {
+	return BoxGradient(x, y, w, h, r, f, color.Transparent, color.Transparent).withStops(stops)
+}
+
+// ImagePattern creates and returns an image pattern. Parameters (ox,oy) specify the left-top location of the image pattern,
+// (w,h) the size of one image, angle rotation around the top-left corner, image is handle to the image to render, and
+// alpha is the transparency of the pattern.
+// The pattern is transformed by the current transform when it is passed to Context.SetFillPaint() or Context.SetStrokePaint().
+func ImagePattern(ox, oy, w, h, angle float32, image int, alpha float32) Paint {
+	xform := RotateMatrix(angle)
+	xform[4] = ox
+	xform[5] = oy
+	tint := color.NRGBA64{R: 0xffff, G: 0xffff, B: 0xffff, A: uint16(clampF(alpha, 0.0, 1.0) * 0xffff)}
+
+	return Paint{
+		Xform:      xform,
+		Extent:     [2]float32{w, h},
+		InnerColor: tint,
+		OuterColor: tint,
+		Image:      image,
+	}
+}
+
+// gradientStopsKey returns the key to find the ramp texture of the stops.
+func gradientStopsKey(stops GradientStops) string {
+	key := make([]byte, 0, len(stops)*20)
+	for _, stop := range stops {
+		r, g, b, a := stop.Color.RGBA()
+		for _, v := range [5]uint32{math.Float32bits(stop.Offset), r, g, b, a} {
+			key = append(key, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+		}
+	}
+	return string(key)
+}
+
+// bakeGradientRamp returns the alpha-premultiplied RGBA pixels of the stops. The first and the last pixels are
+// the colors at the offset 0 and 1.
+func bakeGradientRamp(stops GradientStops, width int) []byte {
+	pixels := make([]byte, width*4)
+	for i := 0; i < width; i++ {
+		rgba := colorToArray(stops.colorAt(float32(i) / float32(width-1)))
+		for j := 0; j < 4; j++ {
+			pixels[i*4+j] = uint8(rgba[j]*255.0 + 0.5)
+		}
+	}
+	return pixels
 }
@@ -0,0 +1,140 @@
+package nanovgo
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// checkPaint compares the geometry of the paint with the expected values.
+func checkPaint(t *testing.T, name string, paint Paint, xform TransformMatrix, extent [2]float32, radius, feather float32) {
+	for i := range xform {
+		if !nearlyEqual(paint.Xform[i], xform[i]) {
+			t.Errorf("%s: Xform should be %v, but %v", name, xform, paint.Xform)
+			break
+		}
+	}
+	if !nearlyEqual(paint.Extent[0], extent[0]) || !nearlyEqual(paint.Extent[1], extent[1]) {
+		t.Errorf("%s: Extent should be %v, but %v", name, extent, paint.Extent)
+	}
+	if !nearlyEqual(paint.Radius, radius) {
+		t.Errorf("%s: Radius should be %f, but %f", name, radius, paint.Radius)
+	}
+	if !nearlyEqual(paint.Feather, feather) {
+		t.Errorf("%s: Feather should be %f, but %f", name, feather, paint.Feather)
+	}
+}
+
+func TestLinearGradient(t *testing.T) {
+	large := nvgGradientLarge
+	paint := LinearGradient(10, 20, 10, 120, red, blue)
+	checkPaint(t, "vertical", paint, TransformMatrix{1, 0, 0, 1, 10, 20 - large}, [2]float32{large, large + 50}, 0, 100)
+	if !sameColor(paint.InnerColor, red) || !sameColor(paint.OuterColor, blue) {
+		t.Error("the colors should be the start and end colors")
+	}
+
+	paint = LinearGradient(0, 0, 30, 0, red, blue)
+	checkPaint(t, "horizontal", paint, TransformMatrix{0, -1, 1, 0, -large, 0}, [2]float32{large, large + 15}, 0, 30)
+
+	// The gradient of the same start and end points goes downward with the minimum feather.
+	paint = LinearGradient(5, 5, 5, 5, red, blue)
+	checkPaint(t, "degenerate", paint, TransformMatrix{1, 0, 0, 1, 5, 5 - large}, [2]float32{large, large}, 0, 1)
+}
+
+func TestRadialGradient(t *testing.T) {
+	paint := RadialGradient(50, 60, 10, 30, red, blue)
+	checkPaint(t, "radial", paint, TranslateMatrix(50, 60), [2]float32{20, 20}, 20, 20)
+	if !sameColor(paint.InnerColor, red) || !sameColor(paint.OuterColor, blue) {
+		t.Error("the colors should be the inner and outer colors")
+	}
+
+	paint = RadialGradient(0, 0, 10, 10, red, blue)
+	checkPaint(t, "same radii", paint, TranslateMatrix(0, 0), [2]float32{10, 10}, 10, 1)
+}
+
+func TestBoxGradient(t *testing.T) {
+	paint := BoxGradient(10, 20, 100, 50, 5, 8, red, blue)
+	checkPaint(t, "box", paint, TranslateMatrix(60, 45), [2]float32{50, 25}, 5, 8)
+	if !sameColor(paint.InnerColor, red) || !sameColor(paint.OuterColor, blue) {
+		t.Error("the colors should be the inner and outer colors")
+	}
+
+	paint = BoxGradient(0, 0, 10, 10, 0, 0, red, blue)
+	checkPaint(t, "no feather", paint, TranslateMatrix(5, 5), [2]float32{5, 5}, 0, 1)
+}
+
+func TestImagePattern(t *testing.T) {
+	paint := ImagePattern(10, 20, 30, 40, 0, 3, 0.5)
+	checkPaint(t, "image", paint, TranslateMatrix(10, 20), [2]float32{30, 40}, 0, 0)
+	if paint.Image != 3 {
+		t.Errorf("Image should be 3, but %d", paint.Image)
+	}
+	if a := straightColor(paint.InnerColor)[3]; !nearlyEqual(a, 0.5) || !sameColor(paint.InnerColor, paint.OuterColor) {
+		t.Errorf("the tint should have the alpha 0.5, but %v", paint.InnerColor)
+	}
+
+	paint = ImagePattern(10, 20, 30, 40, PI/2, 3, 2.0)
+	checkPaint(t, "rotated", paint, TransformMatrix{0, 1, -1, 0, 10, 20}, [2]float32{30, 40}, 0, 0)
+	if a := straightColor(paint.InnerColor)[3]; a != 1.0 {
+		t.Errorf("the alpha should be clamped to 1, but %f", a)
+	}
+}
+
+// newQuadrantImage creates the 4x4 image that has red, blue, green and white quadrants.
+func newQuadrantImage(ctx *Context) int {
+	src := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c := []color.RGBA{red, blue, green, white}[x/2+y/2*2]
+			src.SetRGBA(x, y, c)
+		}
+	}
+	return ctx.CreateImage(src)
+}
+
+func TestImagePatternPlacement(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 32, 32, 0)
+	img := newQuadrantImage(ctx)
+	ctx.BeginPath()
+	ctx.Rect(8, 8, 16, 16)
+	ctx.SetFillImage(8, 8, 16, 16, 0, img, 1.0)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 12, 12, red)
+	checkPixel(t, dst, 19, 12, blue)
+	checkPixel(t, dst, 12, 19, green)
+	checkPixel(t, dst, 19, 19, white)
+	checkPixel(t, dst, 4, 4, transparent)
+}
+
+func TestImagePatternRotation(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 16, 16, 0)
+	img := newQuadrantImage(ctx)
+	// The image rotates around the top-left corner at (16,0), so its x axis goes downward.
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 16)
+	ctx.SetFillPaint(ImagePattern(16, 0, 16, 16, PI/2, img, 1.0))
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 12, 3, red)
+	checkPixel(t, dst, 12, 12, blue)
+	checkPixel(t, dst, 3, 3, green)
+	checkPixel(t, dst, 3, 12, white)
+}
+
+func TestImagePatternPremultiplied(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 16, 16, 0)
+	// image.RGBA is alpha-premultiplied, so the colors are not multiplied by the alpha again.
+	src := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	src.SetRGBA(0, 0, color.RGBA{R: 128, A: 128})
+	img := ctx.CreateImage(src)
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 16)
+	ctx.SetFillImage(0, 0, 16, 16, 0, img, 1.0)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 8, 8, color.RGBA{R: 128, A: 128})
+}
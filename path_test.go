@@ -0,0 +1,187 @@
+package nanovgo
+
+import (
+	"testing"
+)
+
+func TestStrokeSinglePointPath(t *testing.T) {
+	draws := map[string]func(ctx *Context){
+		"trailing MoveTo": func(ctx *Context) {
+			ctx.MoveTo(4, 8)
+			ctx.LineTo(28, 8)
+			ctx.MoveTo(1, 1)
+		},
+		"MoveTo between paths": func(ctx *Context) {
+			ctx.MoveTo(4, 8)
+			ctx.LineTo(28, 8)
+			ctx.MoveTo(1, 1)
+			ctx.MoveTo(4, 24)
+			ctx.LineTo(28, 24)
+		},
+		"dashed": func(ctx *Context) {
+			ctx.SetLineDash([]float32{8, 4})
+			ctx.MoveTo(4, 8)
+			ctx.LineTo(28, 8)
+			ctx.MoveTo(1, 1)
+		},
+	}
+	for name, draw := range draws {
+		ctx, dst := newTestImageContext(t, 32, 32, AntiAlias)
+		ctx.BeginPath()
+		draw(ctx)
+		ctx.SetStrokeWidth(4)
+		ctx.SetStrokeColor(red)
+		ctx.Stroke()
+		ctx.EndFrame()
+
+		if dst.RGBAAt(6, 8) != red {
+			t.Errorf("%s: the line should be stroked, but %v", name, dst.RGBAAt(6, 8))
+		}
+		checkPixel(t, dst, 1, 1, transparent)
+	}
+}
+
+func TestLineToWithoutMoveTo(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 32, 32, AntiAlias)
+	ctx.BeginPath()
+	ctx.LineTo(30, 30)
+	ctx.BezierTo(30, 0, 0, 30, 30, 30)
+	ctx.Rect(8, 8, 16, 16)
+	ctx.SetFillColor(red)
+	ctx.Fill()
+	ctx.SetStrokeColor(red)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 16, 16, red)
+	checkPixel(t, dst, 28, 28, transparent)
+}
+
+// checkCommands compares the path commands with the expected values.
+func checkCommands(t *testing.T, name string, commands, expected []float32) {
+	if len(commands) != len(expected) {
+		t.Errorf("%s: commands should be %v, but %v", name, expected, commands)
+		return
+	}
+	for i := range expected {
+		if absF(commands[i]-expected[i]) > 0.001 {
+			t.Errorf("%s: commands should be %v, but %v", name, expected, commands)
+			return
+		}
+	}
+}
+
+func TestArc(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 32, 32, AntiAlias)
+	ctx.BeginPath()
+	ctx.Arc(0, 0, 10, 0, PI*0.5, Clockwise)
+	k := 10 * Kappa90
+	checkCommands(t, "quarter arc", ctx.commands, []float32{
+		float32(nvgMOVETO), 10, 0,
+		float32(nvgBEZIERTO), 10, k, k, 10, 0, 10,
+	})
+
+	ctx.BeginPath()
+	ctx.Arc(0, 0, 10, 0, PI*0.5, CounterClockwise)
+	if n := countBeziers(ctx.commands); n != 3 {
+		t.Errorf("counterclockwise arc should be split into 3 segments, but %d", n)
+	}
+
+	// Large radii need more segments to keep the error less than the tessellation tolerance.
+	ctx.BeginPath()
+	ctx.Arc(0, 0, 10000, 0, PI*0.5, Clockwise)
+	if n := countBeziers(ctx.commands); n < 2 {
+		t.Errorf("large arc should be split into segments, but %d", n)
+	}
+}
+
+func TestArcZeroRadius(t *testing.T) {
+	ctx, dst := newTestImageContext(t, 32, 32, AntiAlias)
+	ctx.BeginPath()
+	ctx.Arc(16, 16, 0, 0, PI, Clockwise)
+	checkCommands(t, "first arc", ctx.commands, []float32{float32(nvgMOVETO), 16, 16})
+	ctx.SetStrokeColor(red)
+	ctx.Stroke()
+
+	ctx.BeginPath()
+	ctx.MoveTo(4, 16)
+	ctx.Arc(16, 16, 0, 0, PI, Clockwise)
+	checkCommands(t, "following arc", ctx.commands, []float32{
+		float32(nvgMOVETO), 4, 16,
+		float32(nvgLINETO), 16, 16,
+	})
+	ctx.SetStrokeWidth(4)
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	checkPixel(t, dst, 10, 16, red)
+}
+
+func TestArcTo(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 32, 32, AntiAlias)
+	ctx.BeginPath()
+	ctx.MoveTo(0, 0)
+	ctx.ArcTo(10, 0, 10, 10, 5)
+	if len(ctx.commands) < 6 || nvgCommands(ctx.commands[3]) != nvgLINETO {
+		t.Fatalf("ArcTo() should start with the line to the tangent point, but %v", ctx.commands)
+	}
+	checkCommands(t, "tangent point", ctx.commands[4:6], []float32{5, 0})
+	if absF(ctx.commandX-10) > 0.001 || absF(ctx.commandY-5) > 0.001 {
+		t.Errorf("ArcTo() should end at the second tangent point, but (%f, %f)", ctx.commandX, ctx.commandY)
+	}
+
+	// Degenerate cases draw the line to (x1,y1).
+	for name, args := range map[string][5]float32{
+		"zero radius":    {10, 0, 10, 10, 0},
+		"collinear":      {10, 0, 20, 0, 5},
+		"same as start":  {0, 0, 10, 10, 5},
+		"same as second": {10, 0, 10, 0, 5},
+	} {
+		ctx.BeginPath()
+		ctx.MoveTo(0, 0)
+		ctx.ArcTo(args[0], args[1], args[2], args[3], args[4])
+		checkCommands(t, name, ctx.commands, []float32{
+			float32(nvgMOVETO), 0, 0,
+			float32(nvgLINETO), args[0], args[1],
+		})
+	}
+}
+
+func TestDistPtSeg(t *testing.T) {
+	cases := []struct {
+		x, y     float32
+		expected float32
+	}{
+		{5, 3, 9},
+		{-3, 4, 25},
+		{20, 0, 100},
+		{13, 4, 25},
+	}
+	for _, c := range cases {
+		if d := distPtSeg(c.x, c.y, 0, 0, 10, 0); absF(d-c.expected) > 0.001 {
+			t.Errorf("squared distance from (%f, %f) should be %f, but %f", c.x, c.y, c.expected, d)
+		}
+	}
+	if d := distPtSeg(3, 4, 1, 1, 1, 1); absF(d-13) > 0.001 {
+		t.Errorf("squared distance to the point should be 13, but %f", d)
+	}
+}
+
+// countBeziers returns the number of the bezier commands.
+func countBeziers(commands []float32) int {
+	n := 0
+	for i := 0; i < len(commands); {
+		switch nvgCommands(commands[i]) {
+		case nvgMOVETO, nvgLINETO:
+			i += 3
+		case nvgBEZIERTO:
+			n++
+			i += 7
+		case nvgWINDING:
+			i += 2
+		default:
+			i++
+		}
+	}
+	return n
+}
@@ -0,0 +1,760 @@
+package nanovgo
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shibukawa/nanovgo/fontstashmini/truetype"
+)
+
+// NewPDFContext makes new NanoVGo context that writes a PDF document to w.
+// Each frame between Context.BeginFrame() and Context.EndFrame() becomes a page that has the window size in points.
+// Paths keep their curves, gradients become shadings, scissors become clip paths, and TrueType fonts loaded
+// by Context.CreateFont() are embedded. The document is completed when Context.Delete() is called.
+func NewPDFContext(w io.Writer) (*Context, error) {
+	return NewContextWithRenderer(&pdfContext{
+		w:      w,
+		images: make(map[int]int),
+		fonts:  make(map[int]*pdfFont),
+	})
+}
+
+type pdfContext struct {
+	imageTextures
+	w       io.Writer
+	err     error
+	offset  int
+	offsets []int
+	pageIDs []int
+
+	inPage    bool
+	width     float32
+	height    float32
+	content   bytes.Buffer
+	resources pdfResources
+
+	images   map[int]int
+	fonts    map[int]*pdfFont
+	fontList []*pdfFont
+
+	clip      *Clip
+	mask      int
+	blendMode BlendMode
+	fillRule  FillRule
+}
+
+type pdfFont struct {
+	id     int
+	name   string
+	data   []byte
+	info   *truetype.FontInfo
+	scale  float32
+	unit   float32
+	glyphs map[int]rune
+}
+
+// pdfResources keeps the resource dictionary of the current page.
+type pdfResources struct {
+	names   map[string]string
+	entries map[string][]string
+}
+
+var pdfResourceCategories = []string{"ExtGState", "Pattern", "XObject", "Font"}
+
+func (r *pdfResources) reset() {
+	r.names = make(map[string]string)
+	r.entries = make(map[string][]string)
+}
+
+// add registers the value to the category, and returns its name. Same values share the name.
+func (r *pdfResources) add(category, prefix, value string) string {
+	key := category + value
+	if name, ok := r.names[key]; ok {
+		return name
+	}
+	name := prefix + strconv.Itoa(len(r.names)+1)
+	r.names[key] = name
+	r.entries[category] = append(r.entries[category], "/"+name+" "+value)
+	return name
+}
+
+func (r *pdfResources) String() string {
+	var buf bytes.Buffer
+	buf.WriteString("<<")
+	for _, category := range pdfResourceCategories {
+		entries := r.entries[category]
+		if len(entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, " /%s << %s >>", category, strings.Join(entries, " "))
+	}
+	buf.WriteString(" >>")
+	return buf.String()
+}
+
+func (c *pdfContext) EdgeAntiAlias() bool {
+	return false
+}
+
+func (c *pdfContext) Create() error {
+	if c.w == nil {
+		return errors.New("output writer is nil")
+	}
+	// Object 1 is the catalog and object 2 is the page tree. They are written by Delete().
+	c.newObject()
+	c.newObject()
+	c.write([]byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"))
+	return c.err
+}
+
+func (c *pdfContext) DeleteTexture(image int) error {
+	delete(c.images, image)
+	return c.imageTextures.DeleteTexture(image)
+}
+
+func (c *pdfContext) UpdateTexture(image, x, y, w, h int, data []byte) error {
+	delete(c.images, image)
+	return c.imageTextures.UpdateTexture(image, x, y, w, h, data)
+}
+
+func (c *pdfContext) Viewport(width, height int) {
+	c.inPage = true
+	c.width = float32(width)
+	c.height = float32(height)
+	c.content.Reset()
+	c.resources.reset()
+	// Flip the page to use the same coordinates as the canvas.
+	fmt.Fprintf(&c.content, "1 0 0 -1 0 %s cm\n", pdfFloat(c.height))
+}
+
+func (c *pdfContext) Cancel() {
+	c.inPage = false
+}
+
+func (c *pdfContext) Flush() {
+	if !c.inPage {
+		return
+	}
+	c.inPage = false
+	contentID := c.newObject()
+	c.writeObject(contentID, "", c.content.Bytes())
+	pageID := c.newObject()
+	c.writeObject(pageID, fmt.Sprintf("/Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources %s /Contents %d 0 R",
+		pdfFloat(c.width), pdfFloat(c.height), c.resources.String(), contentID), nil)
+	c.pageIDs = append(c.pageIDs, pageID)
+}
+
+func (c *pdfContext) Fill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []Path) {
+}
+
+func (c *pdfContext) Stroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []Path) {
+}
+
+func (c *pdfContext) Triangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+}
+
+func (c *pdfContext) TriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+}
+
+// Delete writes the fonts, the page tree and the cross-reference table to complete the document.
+func (c *pdfContext) Delete() {
+	for _, font := range c.fontList {
+		c.writeFont(font)
+	}
+	kids := make([]string, len(c.pageIDs))
+	for i, id := range c.pageIDs {
+		kids[i] = fmt.Sprintf("%d 0 R", id)
+	}
+	c.writeObject(2, fmt.Sprintf("/Type /Pages /Kids [%s] /Count %d", strings.Join(kids, " "), len(c.pageIDs)), nil)
+	c.writeObject(1, "/Type /Catalog /Pages 2 0 R", nil)
+
+	xref := c.offset
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(c.offsets)+1)
+	for _, offset := range c.offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(c.offsets)+1, xref)
+	c.write(buf.Bytes())
+	if c.err != nil {
+		log.Printf("can't write PDF document: %v\n", c.err)
+	}
+	c.textures = nil
+	c.images = nil
+	c.fonts = nil
+}
+
+func (c *pdfContext) fillPath(paint *Paint, scissor *Scissor, commands []float32) {
+	subpaths := splitSubpaths(commands, true)
+	if len(subpaths) == 0 || !c.inPage {
+		return
+	}
+	c.content.WriteString("q\n")
+	c.setScissor(scissor)
+	c.writeClip()
+	c.writeMask()
+	c.writeBlendMode()
+	c.setPaint(paint, false)
+	c.writePath(subpaths, true)
+	if c.fillRule == EvenOdd {
+		c.content.WriteString("f*\nQ\n")
+	} else {
+		c.content.WriteString("f\nQ\n")
+	}
+}
+
+func (c *pdfContext) strokePath(paint *Paint, scissor *Scissor, style *nvgStrokeStyle, commands []float32) {
+	subpaths := splitSubpaths(commands, false)
+	if len(subpaths) == 0 || !c.inPage {
+		return
+	}
+	c.content.WriteString("q\n")
+	c.setScissor(scissor)
+	c.writeClip()
+	c.writeMask()
+	c.writeBlendMode()
+	c.setPaint(paint, true)
+	fmt.Fprintf(&c.content, "%s w %d J %d j %s M\n", pdfFloat(style.width), pdfLineCap(style.lineCap), pdfLineJoin(style.lineJoin), pdfFloat(maxF(1.0, style.miterLimit)))
+	if len(style.lineDash) > 0 {
+		dash := make([]string, len(style.lineDash))
+		for i, length := range style.lineDash {
+			dash[i] = pdfFloat(length)
+		}
+		// Each subpath is stroked separately, so the pattern continues from the end of the previous subpath.
+		offset := style.dashOffset
+		for _, subpath := range subpaths {
+			fmt.Fprintf(&c.content, "[%s] %s d\n", strings.Join(dash, " "), pdfFloat(offset))
+			c.writePath([]nvgSubpath{subpath}, false)
+			c.content.WriteString("S\n")
+			offset += subpath.length()
+		}
+		c.content.WriteString("Q\n")
+		return
+	}
+	c.writePath(subpaths, false)
+	c.content.WriteString("S\nQ\n")
+}
+
+func (c *pdfContext) text(paint *Paint, scissor *Scissor, run *nvgTextRun) {
+	if len(run.runes) == 0 || run.fontData == nil || !c.inPage {
+		return
+	}
+	font := c.font(run)
+	if font == nil {
+		return
+	}
+	name := c.resources.add("Font", "F", fmt.Sprintf("%d 0 R", font.id))
+
+	c.content.WriteString("q\n")
+	c.setScissor(scissor)
+	c.writeClip()
+	c.writeMask()
+	c.writeBlendMode()
+	c.setPaint(paint, false)
+	// Text space is y-up, but the page is flipped.
+	tm := TransformMatrix{1, 0, 0, -1, run.x, run.y}.Multiply(run.xform)
+	fmt.Fprintf(&c.content, "BT\n/%s %s Tf\n%s Tc\n%s Tm\n[", name, pdfFloat(run.fontSize*font.scale), pdfFloat(run.letterSpacing), pdfMatrix(tm))
+	prev := -1
+	for _, r := range run.runes {
+		glyph := font.info.FindGlyphIndex(int(r))
+		if prev != -1 {
+			if kern := font.info.GetGlyphKernAdvance(prev, glyph); kern != 0 {
+				fmt.Fprintf(&c.content, "%s", pdfFloat(-float32(kern)*font.unit))
+			}
+		}
+		fmt.Fprintf(&c.content, "<%04x>", glyph)
+		font.glyphs[glyph] = r
+		prev = glyph
+	}
+	c.content.WriteString("] TJ\nET\nQ\n")
+}
+
+func (c *pdfContext) SetClip(clip *Clip) {
+	c.clip = clip
+}
+
+// writeClip intersects the clipping path of the graphics state with the clip and its parents.
+func (c *pdfContext) writeClip() {
+	for clip := c.clip; clip != nil; clip = clip.Parent {
+		subpaths := splitSubpaths(clip.commands, true)
+		if len(subpaths) == 0 {
+			// Empty clip path hides everything.
+			c.content.WriteString("0 0 m h W n\n")
+			continue
+		}
+		c.writePath(subpaths, true)
+		if clip.FillRule == EvenOdd {
+			c.content.WriteString("W* n\n")
+		} else {
+			c.content.WriteString("W n\n")
+		}
+	}
+}
+
+func (c *pdfContext) SetMask(image int) {
+	c.mask = image
+}
+
+// writeMask sets the soft mask that draws the mask image over the whole page.
+// Gradients that have alpha replace the soft mask, so the mask is not applied to them.
+func (c *pdfContext) writeMask() {
+	if c.mask == 0 {
+		return
+	}
+	imageID := c.image(c.mask)
+	if imageID == 0 {
+		return
+	}
+	formID := c.newObject()
+	w, h := pdfFloat(c.width), pdfFloat(c.height)
+	form := fmt.Sprintf("q %s 0 0 -%s 0 %s cm /Im1 Do Q", w, h, h)
+	c.writeObject(formID, fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %s %s] /Group << /S /Transparency >> /Resources << /XObject << /Im1 %d 0 R >> >>",
+		w, h, imageID), []byte(form))
+	name := c.resources.add("ExtGState", "GS", fmt.Sprintf("<< /SMask << /S /Alpha /G %d 0 R >> >>", formID))
+	fmt.Fprintf(&c.content, "/%s gs\n", name)
+}
+
+func (c *pdfContext) SetBlendMode(mode BlendMode) {
+	c.blendMode = mode
+}
+
+func (c *pdfContext) SetFillRule(rule FillRule) {
+	c.fillRule = rule
+}
+
+func (c *pdfContext) writeBlendMode() {
+	if c.blendMode == BlendNormal {
+		return
+	}
+	name := c.resources.add("ExtGState", "GS", fmt.Sprintf("<< /BM /%s >>", pdfBlendMode(c.blendMode)))
+	fmt.Fprintf(&c.content, "/%s gs\n", name)
+}
+
+func (c *pdfContext) setScissor(scissor *Scissor) {
+	if scissor.Extent[0] < -0.5 || scissor.Extent[1] < -0.5 {
+		return
+	}
+	ex, ey := scissor.Extent[0], scissor.Extent[1]
+	x0, y0 := scissor.Xform.TransformPoint(-ex, -ey)
+	x1, y1 := scissor.Xform.TransformPoint(ex, -ey)
+	x2, y2 := scissor.Xform.TransformPoint(ex, ey)
+	x3, y3 := scissor.Xform.TransformPoint(-ex, ey)
+	fmt.Fprintf(&c.content, "%s %s m %s %s l %s %s l %s %s l h W n\n",
+		pdfFloat(x0), pdfFloat(y0), pdfFloat(x1), pdfFloat(y1), pdfFloat(x2), pdfFloat(y2), pdfFloat(x3), pdfFloat(y3))
+}
+
+func (c *pdfContext) writePath(subpaths []nvgSubpath, fill bool) {
+	for _, subpath := range subpaths {
+		fmt.Fprintf(&c.content, "%s %s m\n", pdfFloat(subpath.x), pdfFloat(subpath.y))
+		for _, segment := range subpath.segments {
+			pts := segment.points
+			if segment.command == nvgBEZIERTO {
+				fmt.Fprintf(&c.content, "%s %s %s %s %s %s c\n", pdfFloat(pts[0]), pdfFloat(pts[1]), pdfFloat(pts[2]), pdfFloat(pts[3]), pdfFloat(pts[4]), pdfFloat(pts[5]))
+			} else {
+				fmt.Fprintf(&c.content, "%s %s l\n", pdfFloat(pts[0]), pdfFloat(pts[1]))
+			}
+		}
+		if fill || subpath.closed {
+			c.content.WriteString("h\n")
+		}
+	}
+}
+
+// setPaint writes the operators to use the paint for filling or stroking.
+func (c *pdfContext) setPaint(paint *Paint, stroke bool) {
+	colorOp, patternOp, spaceOp, alphaKey := "rg", "scn", "cs", "ca"
+	if stroke {
+		colorOp, patternOp, spaceOp, alphaKey = "RG", "SCN", "CS", "CA"
+	}
+	paintType := vectorPaintTypeOf(paint)
+	if paintType == vectorPaintColor {
+		rgba := straightColor(paint.InnerColor)
+		c.setAlpha(alphaKey, rgba[3])
+		fmt.Fprintf(&c.content, "%s %s %s %s\n", pdfFloat(rgba[0]), pdfFloat(rgba[1]), pdfFloat(rgba[2]), colorOp)
+		return
+	}
+
+	// Patterns are in the default coordinates of the page.
+	matrix := paint.Xform.Multiply(TransformMatrix{1, 0, 0, -1, 0, c.height})
+	var patternID int
+	if paintType == vectorPaintImage {
+		patternID = c.imagePattern(paint, matrix)
+		if patternID == 0 {
+			// The image is not found.
+			c.setAlpha(alphaKey, 0.0)
+			return
+		}
+		c.setAlpha(alphaKey, straightColor(paint.InnerColor)[3])
+	} else {
+		patternID = c.newObject()
+		c.writeObject(patternID, fmt.Sprintf("/Type /Pattern /PatternType 2 /Shading %d 0 R /Matrix [%s]",
+			c.shading(paint, "/DeviceRGB", pdfRGB), pdfMatrix(matrix)), nil)
+		if sameAlpha(paint.gradientStops()) {
+			c.setAlpha(alphaKey, straightColor(paint.InnerColor)[3])
+		} else {
+			c.setAlphaMask(paint)
+		}
+	}
+	name := c.resources.add("Pattern", "P", fmt.Sprintf("%d 0 R", patternID))
+	fmt.Fprintf(&c.content, "/Pattern %s /%s %s\n", spaceOp, name, patternOp)
+}
+
+func (c *pdfContext) setAlpha(key string, alpha float32) {
+	if alpha >= 1.0 {
+		return
+	}
+	name := c.resources.add("ExtGState", "GS", fmt.Sprintf("<< /%s %s >>", key, pdfFloat(alpha)))
+	fmt.Fprintf(&c.content, "/%s gs\n", name)
+}
+
+// setAlphaMask uses the alpha of the gradient as the soft mask.
+func (c *pdfContext) setAlphaMask(paint *Paint) {
+	shadingID := c.shading(paint, "/DeviceGray", pdfAlpha)
+	formID := c.newObject()
+	form := fmt.Sprintf("q %s cm /Sh1 sh Q", pdfMatrix(paint.Xform))
+	c.writeObject(formID, fmt.Sprintf("/Type /XObject /Subtype /Form /BBox [0 0 %s %s] /Group << /S /Transparency /CS /DeviceGray >> /Resources << /Shading << /Sh1 %d 0 R >> >>",
+		pdfFloat(c.width), pdfFloat(c.height), shadingID), []byte(form))
+	name := c.resources.add("ExtGState", "GS", fmt.Sprintf("<< /SMask << /S /Luminosity /G %d 0 R >> >>", formID))
+	fmt.Fprintf(&c.content, "/%s gs\n", name)
+}
+
+// shading writes the shading of the gradient paint in the paint coordinates.
+// components converts the colors of the paint into the components of the color space.
+func (c *pdfContext) shading(paint *Paint, colorSpace string, components func(color.Color) string) int {
+	id := c.newObject()
+	switch vectorPaintTypeOf(paint) {
+	case vectorPaintLinear:
+		y0 := paint.Extent[1] - paint.Feather*0.5
+		y1 := paint.Extent[1] + paint.Feather*0.5
+		c.writeObject(id, fmt.Sprintf("/ShadingType 2 /ColorSpace %s /Coords [0 %s 0 %s] /Function %s /Extend [true true]",
+			colorSpace, pdfFloat(y0), pdfFloat(y1), pdfStopsFunction(paint.gradientStops(), components)), nil)
+	case vectorPaintRadial:
+		radius, inner, stops := radialGradientStops(paint)
+		c.writeObject(id, fmt.Sprintf("/ShadingType 3 /ColorSpace %s /Coords [0 0 %s 0 0 %s] /Function %s /Extend [true true]",
+			colorSpace, pdfFloat(inner*radius), pdfFloat(radius), pdfStopsFunction(stops, components)), nil)
+	default:
+		// Box and conic gradients are evaluated by a PostScript calculator function.
+		functionID := c.newObject()
+		var code bytes.Buffer
+		if paint.Conic {
+			code.WriteString("{ exch atan 360 div ")
+		} else {
+			fmt.Fprintf(&code, "{ abs %s sub exch abs %s sub 2 copy 2 copy lt {exch} if pop dup 0 gt {pop 0} if 3 1 roll ",
+				pdfFloat(paint.Extent[1]-paint.Radius), pdfFloat(paint.Extent[0]-paint.Radius))
+			fmt.Fprintf(&code, "dup 0 lt {pop 0} if dup mul exch dup 0 lt {pop 0} if dup mul add sqrt add %s sub %s add %s div ",
+				pdfFloat(paint.Radius), pdfFloat(paint.Feather*0.5), pdfFloat(paint.Feather))
+		}
+		code.WriteString("dup 0 lt {pop 0} if dup 1 gt {pop 1} if ")
+		stopsCode, n := pdfStopsCode(paint.gradientStops(), components)
+		code.WriteString(stopsCode)
+		code.WriteString(" }")
+		ranges := strings.TrimSpace(strings.Repeat("0 1 ", n))
+		large := pdfFloat(nvgGradientLarge)
+		c.writeObject(functionID, fmt.Sprintf("/FunctionType 4 /Domain [-%s %s -%s %s] /Range [%s]", large, large, large, large, ranges), code.Bytes())
+		c.writeObject(id, fmt.Sprintf("/ShadingType 1 /ColorSpace %s /Domain [-%s %s -%s %s] /Function %d 0 R",
+			colorSpace, large, large, large, large, functionID), nil)
+	}
+	return id
+}
+
+func (c *pdfContext) imagePattern(paint *Paint, matrix TransformMatrix) int {
+	imageID := c.image(paint.Image)
+	if imageID == 0 {
+		return 0
+	}
+	w, h := pdfFloat(paint.Extent[0]), pdfFloat(paint.Extent[1])
+	id := c.newObject()
+	content := fmt.Sprintf("q %s 0 0 -%s 0 %s cm /Im1 Do Q", w, h, h)
+	c.writeObject(id, fmt.Sprintf("/Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 %s %s] /XStep %s /YStep %s /Matrix [%s] /Resources << /XObject << /Im1 %d 0 R >> >>",
+		w, h, w, h, pdfMatrix(matrix), imageID), []byte(content))
+	return id
+}
+
+// image writes the texture as image XObject. The alpha channel becomes the soft mask.
+func (c *pdfContext) image(image int) int {
+	if id, ok := c.images[image]; ok {
+		return id
+	}
+	tex := c.findTexture(image)
+	if tex == nil {
+		return 0
+	}
+	rgb := make([]byte, 0, tex.width*tex.height*3)
+	alpha := make([]byte, 0, tex.width*tex.height)
+	if tex.texType == TextureRGBA {
+		for i := 0; i < len(tex.data); i += 4 {
+			r, g, b, a := tex.data[i], tex.data[i+1], tex.data[i+2], tex.data[i+3]
+			if a != 0 && a != 255 {
+				// Pixels are alpha-premultiplied.
+				r = uint8(uint32(r) * 255 / uint32(a))
+				g = uint8(uint32(g) * 255 / uint32(a))
+				b = uint8(uint32(b) * 255 / uint32(a))
+			}
+			rgb = append(rgb, r, g, b)
+			alpha = append(alpha, a)
+		}
+	} else {
+		for _, a := range tex.data {
+			rgb = append(rgb, 255, 255, 255)
+			alpha = append(alpha, a)
+		}
+	}
+	maskID := c.newObject()
+	c.writeObject(maskID, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode",
+		tex.width, tex.height), pdfDeflate(alpha))
+	id := c.newObject()
+	c.writeObject(id, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /SMask %d 0 R /Filter /FlateDecode",
+		tex.width, tex.height, maskID), pdfDeflate(rgb))
+	c.images[image] = id
+	return id
+}
+
+func (c *pdfContext) font(run *nvgTextRun) *pdfFont {
+	if font, ok := c.fonts[run.fontID]; ok {
+		return font
+	}
+	info, err := truetype.InitFont(run.fontData, 0)
+	if err != nil {
+		c.fonts[run.fontID] = nil
+		return nil
+	}
+	font := &pdfFont{
+		id:     c.newObject(),
+		name:   run.fontName,
+		data:   run.fontData,
+		info:   info,
+		scale:  float32(info.ScaleForPixelHeight(1.0) / info.ScaleForMappingEmToPixels(1.0)),
+		unit:   float32(info.ScaleForMappingEmToPixels(1000.0)),
+		glyphs: make(map[int]rune),
+	}
+	c.fonts[run.fontID] = font
+	c.fontList = append(c.fontList, font)
+	return font
+}
+
+// writeFont embeds the TrueType font as CID font. Text uses glyph indexes as CIDs.
+func (c *pdfContext) writeFont(font *pdfFont) {
+	glyphs := make([]int, 0, len(font.glyphs))
+	for glyph := range font.glyphs {
+		glyphs = append(glyphs, glyph)
+	}
+	sort.Ints(glyphs)
+
+	var widths, cmap bytes.Buffer
+	for _, glyph := range glyphs {
+		advance, _ := font.info.GetGlyphHMetrics(glyph)
+		fmt.Fprintf(&widths, "%d [%s] ", glyph, pdfFloat(float32(advance)*font.unit))
+		fmt.Fprintf(&cmap, "<%04x> <%s>\n", glyph, pdfUTF16(font.glyphs[glyph]))
+	}
+	toUnicode := fmt.Sprintf("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n1 begincodespacerange\n<0000> <ffff>\nendcodespacerange\n%d beginbfchar\n%sendbfchar\nendcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n",
+		len(glyphs), cmap.String())
+
+	ascent, descent, _ := font.info.GetFontVMetrics()
+	x0, y0, x1, y1 := font.info.GetFontBoundingBox()
+	name := pdfName(font.name)
+
+	fileID := c.newObject()
+	c.writeObject(fileID, fmt.Sprintf("/Length1 %d /Filter /FlateDecode", len(font.data)), pdfDeflate(font.data))
+	descriptorID := c.newObject()
+	c.writeObject(descriptorID, fmt.Sprintf("/Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%s %s %s %s] /ItalicAngle 0 /Ascent %s /Descent %s /CapHeight %s /StemV 80 /FontFile2 %d 0 R",
+		name, pdfFloat(float32(x0)*font.unit), pdfFloat(float32(y0)*font.unit), pdfFloat(float32(x1)*font.unit), pdfFloat(float32(y1)*font.unit),
+		pdfFloat(float32(ascent)*font.unit), pdfFloat(float32(descent)*font.unit), pdfFloat(float32(ascent)*font.unit), fileID), nil)
+	cidFontID := c.newObject()
+	c.writeObject(cidFontID, fmt.Sprintf("/Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity",
+		name, descriptorID, widths.String()), nil)
+	toUnicodeID := c.newObject()
+	c.writeObject(toUnicodeID, "", []byte(toUnicode))
+	c.writeObject(font.id, fmt.Sprintf("/Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R",
+		name, cidFontID, toUnicodeID), nil)
+}
+
+func (c *pdfContext) newObject() int {
+	c.offsets = append(c.offsets, 0)
+	return len(c.offsets)
+}
+
+// writeObject writes the dictionary, and the stream if it is not nil, as the object.
+func (c *pdfContext) writeObject(id int, dict string, stream []byte) {
+	c.offsets[id-1] = c.offset
+	var buf bytes.Buffer
+	if stream != nil {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", id, dict, len(stream))
+		buf.Write(stream)
+		buf.WriteString("\nendstream\nendobj\n")
+	} else {
+		fmt.Fprintf(&buf, "%d 0 obj\n<< %s >>\nendobj\n", id, dict)
+	}
+	c.write(buf.Bytes())
+}
+
+func (c *pdfContext) write(data []byte) {
+	if c.err != nil {
+		return
+	}
+	n, err := c.w.Write(data)
+	c.offset += n
+	c.err = err
+}
+
+func pdfInterpolation(c0, c1 string) string {
+	return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", c0, c1)
+}
+
+// pdfStopsFunction returns the function that interpolates the colors of the stops in the domain [0, 1].
+// Colors out of the stops are the same as the nearest stops.
+func pdfStopsFunction(stops GradientStops, components func(color.Color) string) string {
+	first, last := stops[0], stops[len(stops)-1]
+	if len(stops) <= 2 && first.Offset == 0 && last.Offset == 1 {
+		return pdfInterpolation(components(first.Color), components(last.Color))
+	}
+	points := pdfStopsDomain(stops)
+	var functions, bounds, encode []string
+	for i := 1; i < len(points); i++ {
+		functions = append(functions, pdfInterpolation(components(points[i-1].Color), components(points[i].Color)))
+		encode = append(encode, "0 1")
+		if i < len(points)-1 {
+			bounds = append(bounds, pdfFloat(points[i].Offset))
+		}
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+}
+
+// pdfStopsDomain adds the stops at 0 and 1 to cover the domain of the functions.
+func pdfStopsDomain(stops GradientStops) GradientStops {
+	first, last := stops[0], stops[len(stops)-1]
+	points := stops
+	if first.Offset > 0 {
+		points = append(GradientStops{{Offset: 0, Color: first.Color}}, points...)
+	}
+	if last.Offset < 1 {
+		points = append(points, GradientStop{Offset: 1, Color: last.Color})
+	}
+	return points
+}
+
+// pdfStopsCode returns the PostScript code that replaces the offset on the stack with the components of the color
+// at the offset. It also returns the number of the components.
+func pdfStopsCode(stops GradientStops, components func(color.Color) string) (string, int) {
+	points := pdfStopsDomain(stops)
+	segment := func(k int) string {
+		var code bytes.Buffer
+		c0 := strings.Fields(components(points[k].Color))
+		c1 := strings.Fields(components(points[k+1].Color))
+		if width := points[k+1].Offset - points[k].Offset; width > 0 {
+			fmt.Fprintf(&code, "%s sub %s div", pdfFloat(points[k].Offset), pdfFloat(width))
+		} else {
+			code.WriteString("pop 0")
+		}
+		for i := range c0 {
+			if i < len(c0)-1 {
+				code.WriteString(" dup")
+			}
+			fmt.Fprintf(&code, " %s %s sub mul %s add", c1[i], c0[i], c0[i])
+			if i < len(c0)-1 {
+				code.WriteString(" exch")
+			}
+		}
+		return code.String()
+	}
+	code := segment(len(points) - 2)
+	for k := len(points) - 3; k >= 0; k-- {
+		code = fmt.Sprintf("dup %s lt { %s } { %s } ifelse", pdfFloat(points[k+1].Offset), segment(k), code)
+	}
+	return code, len(strings.Fields(components(stops[0].Color)))
+}
+
+func pdfRGB(c color.Color) string {
+	rgba := straightColor(c)
+	return fmt.Sprintf("%s %s %s", pdfFloat(rgba[0]), pdfFloat(rgba[1]), pdfFloat(rgba[2]))
+}
+
+func pdfAlpha(c color.Color) string {
+	return pdfFloat(straightColor(c)[3])
+}
+
+func pdfMatrix(t TransformMatrix) string {
+	return fmt.Sprintf("%s %s %s %s %s %s", pdfFloat(t[0]), pdfFloat(t[1]), pdfFloat(t[2]), pdfFloat(t[3]), pdfFloat(t[4]), pdfFloat(t[5]))
+}
+
+// pdfFloat formats the number. PDF doesn't accept the exponential notation.
+func pdfFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+// pdfName escapes the characters that can't be used in PDF names.
+func pdfName(name string) string {
+	var buf bytes.Buffer
+	for _, b := range []byte(name) {
+		if b > ' ' && b < '~' && !strings.ContainsRune("#()<>[]{}/%", rune(b)) {
+			buf.WriteByte(b)
+		} else {
+			fmt.Fprintf(&buf, "#%02x", b)
+		}
+	}
+	return buf.String()
+}
+
+func pdfUTF16(r rune) string {
+	if r < 0x10000 {
+		return fmt.Sprintf("%04x", r)
+	}
+	r -= 0x10000
+	return fmt.Sprintf("%04x%04x", 0xd800+(r>>10), 0xdc00+(r&0x3ff))
+}
+
+func pdfDeflate(data []byte) []byte {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func pdfLineCap(lineCap LineCap) int {
+	switch lineCap {
+	case Round:
+		return 1
+	case Square:
+		return 2
+	}
+	return 0
+}
+
+func pdfLineJoin(lineJoin LineCap) int {
+	switch lineJoin {
+	case Round:
+		return 1
+	case Bevel:
+		return 2
+	}
+	return 0
+}
+
+func pdfBlendMode(mode BlendMode) string {
+	switch mode {
+	case BlendMultiply:
+		return "Multiply"
+	case BlendScreen:
+		return "Screen"
+	case BlendOverlay:
+		return "Overlay"
+	case BlendDarken:
+		return "Darken"
+	case BlendLighten:
+		return "Lighten"
+	case BlendColorDodge:
+		return "ColorDodge"
+	case BlendDifference:
+		return "Difference"
+	}
+	return "Normal"
+}
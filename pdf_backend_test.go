@@ -0,0 +1,136 @@
+package nanovgo
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func renderPDF(t *testing.T, pages int, draw func(ctx *Context)) string {
+	var buf bytes.Buffer
+	ctx, err := NewPDFContext(&buf)
+	if err != nil {
+		t.Fatalf("NewPDFContext() failed: %v", err)
+	}
+	for i := 0; i < pages; i++ {
+		ctx.BeginFrame(200, 100, 1.0)
+		draw(ctx)
+		ctx.EndFrame()
+	}
+	ctx.Delete()
+	return buf.String()
+}
+
+func checkPDFStructure(t *testing.T, pdf string, pages int) {
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatal("PDF should have the header and the trailer")
+	}
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(pdf)
+	if startxref == nil {
+		t.Fatal("PDF should have startxref")
+	}
+	xref, _ := strconv.Atoi(startxref[1])
+	if !strings.HasPrefix(pdf[xref:], "xref\n") {
+		t.Fatalf("startxref should point the cross-reference table")
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(pdf[xref:], -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if !strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj\n", i+1)) {
+			t.Errorf("cross-reference entry of object %d is wrong", i+1)
+		}
+	}
+	if !strings.Contains(pdf, "/Type /Pages /Kids [") || !strings.Contains(pdf, fmt.Sprintf("/Count %d", pages)) {
+		t.Errorf("PDF should have %d pages", pages)
+	}
+}
+
+func TestPDFContextPages(t *testing.T) {
+	pdf := renderPDF(t, 2, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 20, 20)
+		ctx.SetFillColor(color.RGBA{R: 255, A: 255})
+		ctx.Fill()
+		ctx.BeginPath()
+		ctx.MoveTo(10, 50)
+		ctx.LineTo(100, 50)
+		ctx.SetStrokeColor(color.NRGBA{B: 255, A: 128})
+		ctx.SetStrokeWidth(2)
+		ctx.Stroke()
+	})
+	checkPDFStructure(t, pdf, 2)
+	for _, expected := range []string{
+		"/MediaBox [0 0 200 100]",
+		"1 0 0 -1 0 100 cm\n",
+		"q\n1 0 0 rg\n10 10 m\n10 30 l\n30 30 l\n30 10 l\nh\nf\nQ\n",
+		"0 0 1 RG\n2 w 0 J 0 j 10 M\n10 50 m\n100 50 l\nS\n",
+	} {
+		if !strings.Contains(pdf, expected) {
+			t.Errorf("PDF should contain %q", expected)
+		}
+	}
+	if !regexp.MustCompile(`/GS\d+ << /CA 0.5`).MatchString(pdf) {
+		t.Error("PDF should use ExtGState for the stroke alpha")
+	}
+}
+
+func TestPDFContextPaints(t *testing.T) {
+	pdf := renderPDF(t, 1, func(ctx *Context) {
+		ctx.Scissor(0, 0, 100, 100)
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 200, 100)
+		ctx.SetFillPaint(LinearGradient(0, 0, 200, 0, color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}))
+		ctx.Fill()
+		ctx.SetFillPaint(RadialGradient(50, 50, 10, 20, color.RGBA{R: 255, A: 255}, color.RGBA{}))
+		ctx.Fill()
+		ctx.SetFillPaint(BoxGradient(10, 10, 50, 50, 5, 10, color.RGBA{A: 255}, color.RGBA{B: 255, A: 255}))
+		ctx.Fill()
+		img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+		ctx.SetFillPaint(ImagePattern(0, 0, 10, 10, 0, ctx.CreateImage(img), 1.0))
+		ctx.Fill()
+	})
+	checkPDFStructure(t, pdf, 1)
+	for _, expected := range []string{
+		"0 0 m 100 0 l 100 100 l 0 100 l h W n\n",
+		"/ShadingType 2 /ColorSpace /DeviceRGB /Coords [0 100000 0 100200]",
+		"/ShadingType 3 /ColorSpace /DeviceRGB /Coords [0 0 10 0 0 20]",
+		"/ShadingType 1 /ColorSpace /DeviceRGB",
+		"/FunctionType 4",
+		"/SMask << /S /Luminosity",
+		"/PatternType 1 /PaintType 1 /TilingType 1 /BBox [0 0 10 10]",
+		"/Subtype /Image /Width 2 /Height 2 /ColorSpace /DeviceRGB",
+	} {
+		if !strings.Contains(pdf, expected) {
+			t.Errorf("PDF should contain %q", expected)
+		}
+	}
+}
+
+func TestPDFContextText(t *testing.T) {
+	pdf := renderPDF(t, 1, func(ctx *Context) {
+		if ctx.CreateFont("sans", "sample/Roboto-Regular.ttf") == -1 {
+			t.Skip("font file is not available")
+		}
+		ctx.SetFontFace("sans")
+		ctx.SetFontSize(20)
+		ctx.Text(10, 50, "Hi")
+	})
+	checkPDFStructure(t, pdf, 1)
+	for _, expected := range []string{
+		"/Subtype /Type0 /BaseFont /sans /Encoding /Identity-H",
+		"/Subtype /CIDFontType2",
+		"/FontFile2",
+		"/ToUnicode",
+		"1 0 0 -1 10 50 Tm\n[<",
+		"] TJ\n",
+	} {
+		if !strings.Contains(pdf, expected) {
+			t.Errorf("PDF should contain %q", expected)
+		}
+	}
+}
@@ -2,20 +2,22 @@ package perfgraph
 
 import (
 	"fmt"
-	"github.com/shibukawa/nanovgo"
+	"image/color"
 	"time"
+
+	"github.com/shibukawa/nanovgo"
 )
 
 const (
 	nvgGraphHistoryCount = 100
 )
 
-var backgroundColor = nanovgo.RGBA(0, 0, 0, 128)
-var graphColor  = nanovgo.RGBA(255, 192, 0, 128)
-var titleTextColor  = nanovgo.RGBA(255, 192, 0, 128)
-var fpsTextColor = nanovgo.RGBA(240, 240, 240, 255)
-var averageTextColor  = nanovgo.RGBA(240, 240, 240, 160)
-var msTextColor = nanovgo.RGBA(240, 240, 240, 255)
+var backgroundColor = color.NRGBA{R: 0, G: 0, B: 0, A: 128}
+var graphColor = color.NRGBA{R: 255, G: 192, B: 0, A: 128}
+var titleTextColor = color.NRGBA{R: 255, G: 192, B: 0, A: 128}
+var fpsTextColor = color.NRGBA{R: 240, G: 240, B: 240, A: 255}
+var averageTextColor = color.NRGBA{R: 240, G: 240, B: 240, A: 160}
+var msTextColor = color.NRGBA{R: 240, G: 240, B: 240, A: 255}
 
 // PerfGraph shows FPS counter on NanoVGo application
 type PerfGraph struct {
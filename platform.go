@@ -11,7 +11,7 @@ type Float float32
 
 var shaderHeader = `
 #define NANOVG_GL2 1
-#define UNIFORMARRAY_SIZE 11
+#define UNIFORMARRAY_SIZE 13
 `
 
 func prepareTextureBuffer(data []byte, w, h, bpp int) []byte {
@@ -0,0 +1,414 @@
+package nanovgo
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"strconv"
+)
+
+// DrawOpKind is the kind of the draw operation recorded by Recorder. Each kind corresponds to the method of Context.
+type DrawOpKind int
+
+// DrawOpKind values. Each of them has the name of the recorded method.
+const (
+	OpSave DrawOpKind = iota
+	OpRestore
+	OpReset
+	OpClearState
+	OpSetStrokeWidth
+	OpSetMiterLimit
+	OpSetLineCap
+	OpSetLineJoin
+	OpSetGlobalAlpha
+	OpSetTransformByValue
+	OpResetTransform
+	OpTranslate
+	OpRotate
+	OpSkewX
+	OpSkewY
+	OpScale
+	OpSetStrokeColor
+	OpSetFillColor
+	OpSetStrokePaint
+	OpSetFillPaint
+	OpCreateImage
+	OpDeleteImage
+	OpScissor
+	OpIntersectScissor
+	OpResetScissor
+	OpBeginPath
+	OpMoveTo
+	OpLineTo
+	OpBezierTo
+	OpQuadTo
+	OpArcTo
+	OpArc
+	OpRect
+	OpRoundedRect
+	OpEllipse
+	OpCircle
+	OpClosePath
+	OpPathWinding
+	OpFill
+	OpStroke
+	OpSetFontSize
+	OpSetTextLetterSpacing
+	OpSetTextLineHeight
+	OpSetTextAlign
+	OpSetFontFaceID
+	OpSetFontFace
+	OpText
+	OpBeginLayer
+	OpEndLayer
+	OpClipPath
+	OpResetClip
+	OpSetMask
+	OpResetMask
+	OpSetGlobalCompositeOperation
+	OpSetGlobalCompositeBlendFunc
+	OpSetGlobalCompositeBlendFuncSeparate
+	OpSetBlendMode
+	OpSetLineDash
+	OpSetLineDashOffset
+	OpSetFillRule
+)
+
+var drawOpNames = []string{
+	"Save", "Restore", "Reset", "ClearState",
+	"SetStrokeWidth", "SetMiterLimit", "SetLineCap", "SetLineJoin", "SetGlobalAlpha",
+	"SetTransformByValue", "ResetTransform", "Translate", "Rotate", "SkewX", "SkewY", "Scale",
+	"SetStrokeColor", "SetFillColor", "SetStrokePaint", "SetFillPaint",
+	"CreateImage", "DeleteImage", "Scissor", "IntersectScissor", "ResetScissor",
+	"BeginPath", "MoveTo", "LineTo", "BezierTo", "QuadTo", "ArcTo", "Arc",
+	"Rect", "RoundedRect", "Ellipse", "Circle", "ClosePath", "PathWinding", "Fill", "Stroke",
+	"SetFontSize", "SetTextLetterSpacing", "SetTextLineHeight", "SetTextAlign", "SetFontFaceID", "SetFontFace", "Text",
+	"BeginLayer", "EndLayer", "ClipPath", "ResetClip", "SetMask", "ResetMask",
+	"SetGlobalCompositeOperation", "SetGlobalCompositeBlendFunc", "SetGlobalCompositeBlendFuncSeparate",
+	"SetBlendMode", "SetLineDash", "SetLineDashOffset",
+	"SetFillRule",
+}
+
+// drawOpArgCounts is the number of the arguments of each kind. Paints have the gradient stops after the fixed
+// arguments, and OpSetLineDash has any number of the lengths.
+var drawOpArgCounts = []int{
+	OpSave: 0, OpRestore: 0, OpReset: 0, OpClearState: 0,
+	OpSetStrokeWidth: 1, OpSetMiterLimit: 1, OpSetLineCap: 1, OpSetLineJoin: 1, OpSetGlobalAlpha: 1,
+	OpSetTransformByValue: 6, OpResetTransform: 0, OpTranslate: 2, OpRotate: 1, OpSkewX: 1, OpSkewY: 1, OpScale: 2,
+	OpSetStrokeColor: 4, OpSetFillColor: 4, OpSetStrokePaint: 20, OpSetFillPaint: 20,
+	OpCreateImage: 1, OpDeleteImage: 1, OpScissor: 4, OpIntersectScissor: 4, OpResetScissor: 0,
+	OpBeginPath: 0, OpMoveTo: 2, OpLineTo: 2, OpBezierTo: 6, OpQuadTo: 4, OpArcTo: 5, OpArc: 6,
+	OpRect: 4, OpRoundedRect: 5, OpEllipse: 4, OpCircle: 3, OpClosePath: 0, OpPathWinding: 1, OpFill: 0, OpStroke: 0,
+	OpSetFontSize: 1, OpSetTextLetterSpacing: 1, OpSetTextLineHeight: 1, OpSetTextAlign: 1, OpSetFontFaceID: 1, OpSetFontFace: 0, OpText: 2,
+	OpBeginLayer: 5, OpEndLayer: 0, OpClipPath: 0, OpResetClip: 0, OpSetMask: 1, OpResetMask: 0,
+	OpSetGlobalCompositeOperation: 1, OpSetGlobalCompositeBlendFunc: 2, OpSetGlobalCompositeBlendFuncSeparate: 4,
+	OpSetBlendMode: 1, OpSetLineDash: 0, OpSetLineDashOffset: 1,
+	OpSetFillRule: 1,
+}
+
+func (k DrawOpKind) String() string {
+	if k < 0 || int(k) >= len(drawOpNames) {
+		return "DrawOpKind(" + strconv.Itoa(int(k)) + ")"
+	}
+	return drawOpNames[k]
+}
+
+// DrawOp is a draw operation recorded by Recorder. Args has the parameters of the method in order.
+// Colors are stored as alpha-premultiplied RGBA components in the range of [0, 1], and paints are stored as
+// Xform, Extent, Radius, Feather, InnerColor, OuterColor and Image. Text has the string parameter, and Image
+// has the pixels of the image created by Context.CreateImage().
+type DrawOp struct {
+	Kind  DrawOpKind
+	Args  []float32   `json:",omitempty"`
+	Text  string      `json:",omitempty"`
+	Image *image.RGBA `json:",omitempty"`
+}
+
+// DisplayList is the list of the recorded draw operations. It can be serialized by encoding/json or encoding/gob.
+type DisplayList []DrawOp
+
+// Recorder records the draw operations issued to a Context. Set it to the context by Context.SetRecorder().
+// The context keeps drawing while it is recorded.
+type Recorder struct {
+	list  DisplayList
+	depth int
+}
+
+// DisplayList returns the recorded draw operations.
+func (r *Recorder) DisplayList() DisplayList {
+	return r.list
+}
+
+// Clear drops the recorded draw operations.
+func (r *Recorder) Clear() {
+	r.list = nil
+}
+
+// SetRecorder starts recording the draw operations into the recorder. Pass nil to stop recording.
+// Fonts are not recorded.
+func (c *Context) SetRecorder(recorder *Recorder) {
+	c.recorder = recorder
+}
+
+// record appends the operation to the recorder, and returns the function to call when the method returns.
+// Operations issued while other operation is running (like Circle() calls Ellipse()) are not recorded.
+func (c *Context) record(kind DrawOpKind, args ...float32) func() {
+	return c.recordOp(DrawOp{Kind: kind, Args: args})
+}
+
+func (c *Context) recordOp(op DrawOp) func() {
+	recorder := c.recorder
+	if recorder.depth == 0 {
+		recorder.list = append(recorder.list, op)
+	}
+	recorder.depth++
+	return func() {
+		recorder.depth--
+	}
+}
+
+// validate returns an error if the operation doesn't have the arguments that the kind needs.
+func (op *DrawOp) validate() error {
+	if op.Kind < 0 || int(op.Kind) >= len(drawOpArgCounts) {
+		return fmt.Errorf("unknown kind %v", op.Kind)
+	}
+	n := drawOpArgCounts[op.Kind]
+	switch op.Kind {
+	case OpSetLineDash:
+		return nil
+	case OpSetStrokePaint, OpSetFillPaint:
+		if len(op.Args) < n || (len(op.Args)-n)%5 != 0 {
+			return fmt.Errorf("%v has %d args, but it needs %d and 5 for each gradient stop", op.Kind, len(op.Args), n)
+		}
+		return nil
+	case OpCreateImage:
+		img := op.Image
+		if img == nil {
+			return fmt.Errorf("%v has no image", op.Kind)
+		}
+		if img.Stride != img.Rect.Dx()*4 || len(img.Pix) != img.Stride*img.Rect.Dy() {
+			return fmt.Errorf("%v has %d bytes of pixels for %v", op.Kind, len(img.Pix), img.Rect)
+		}
+	}
+	if len(op.Args) != n {
+		return fmt.Errorf("%v has %d args, but it needs %d", op.Kind, len(op.Args), n)
+	}
+	return nil
+}
+
+// Replay issues the recorded draw operations to the context.
+// Images recorded by OpCreateImage are created on the context again, and paints and masks use them instead of
+// the recorded handles. Replay returns the handles of them to delete them when they are not needed anymore.
+// Fonts used by the text should be created on the context with the same names or ids.
+//
+// Images created before the recording started and the images of framebuffers are not recorded, so paints and
+// masks use their handles as they are. They are valid only if the list is replayed on the recording context.
+//
+// The list may come from another process, so Replay checks the arguments of each operation before it issues
+// the operation. If an operation is invalid, Replay stops there and returns the error with the images created
+// until then.
+func (l DisplayList) Replay(c *Context) ([]int, error) {
+	images := make(map[int]int)
+	var created []int
+	imageHandle := func(image float32) int {
+		if handle, ok := images[int(image)]; ok {
+			return handle
+		}
+		return int(image)
+	}
+	for i := range l {
+		op := &l[i]
+		if err := op.validate(); err != nil {
+			return created, fmt.Errorf("invalid draw op %d: %v", i, err)
+		}
+		a := op.Args
+		switch op.Kind {
+		case OpSave:
+			c.Save()
+		case OpRestore:
+			c.Restore()
+		case OpReset:
+			c.Reset()
+		case OpClearState:
+			c.ClearState()
+		case OpSetStrokeWidth:
+			c.SetStrokeWidth(a[0])
+		case OpSetMiterLimit:
+			c.SetMiterLimit(a[0])
+		case OpSetLineCap:
+			c.SetLineCap(LineCap(a[0]))
+		case OpSetLineJoin:
+			c.SetLineJoin(LineCap(a[0]))
+		case OpSetGlobalAlpha:
+			c.SetGlobalAlpha(a[0])
+		case OpSetTransformByValue:
+			c.SetTransformByValue(a[0], a[1], a[2], a[3], a[4], a[5])
+		case OpResetTransform:
+			c.ResetTransform()
+		case OpTranslate:
+			c.Translate(a[0], a[1])
+		case OpRotate:
+			c.Rotate(a[0])
+		case OpSkewX:
+			c.SkewX(a[0])
+		case OpSkewY:
+			c.SkewY(a[0])
+		case OpScale:
+			c.Scale(a[0], a[1])
+		case OpSetStrokeColor:
+			c.SetStrokeColor(argsToColor(a))
+		case OpSetFillColor:
+			c.SetFillColor(argsToColor(a))
+		case OpSetStrokePaint:
+			paint := argsToPaint(a)
+			paint.Image = imageHandle(a[18])
+			c.SetStrokePaint(paint)
+		case OpSetFillPaint:
+			paint := argsToPaint(a)
+			paint.Image = imageHandle(a[18])
+			c.SetFillPaint(paint)
+		case OpCreateImage:
+			handle := c.CreateImage(op.Image)
+			images[int(a[0])] = handle
+			created = append(created, handle)
+		case OpDeleteImage:
+			c.DeleteImage(imageHandle(a[0]))
+		case OpScissor:
+			c.Scissor(a[0], a[1], a[2], a[3])
+		case OpIntersectScissor:
+			c.IntersectScissor(a[0], a[1], a[2], a[3])
+		case OpResetScissor:
+			c.ResetScissor()
+		case OpBeginPath:
+			c.BeginPath()
+		case OpMoveTo:
+			c.MoveTo(a[0], a[1])
+		case OpLineTo:
+			c.LineTo(a[0], a[1])
+		case OpBezierTo:
+			c.BezierTo(a[0], a[1], a[2], a[3], a[4], a[5])
+		case OpQuadTo:
+			c.QuadTo(a[0], a[1], a[2], a[3])
+		case OpArcTo:
+			c.ArcTo(a[0], a[1], a[2], a[3], a[4])
+		case OpArc:
+			c.Arc(a[0], a[1], a[2], a[3], a[4], Direction(a[5]))
+		case OpRect:
+			c.Rect(a[0], a[1], a[2], a[3])
+		case OpRoundedRect:
+			c.RoundedRect(a[0], a[1], a[2], a[3], a[4])
+		case OpEllipse:
+			c.Ellipse(a[0], a[1], a[2], a[3])
+		case OpCircle:
+			c.Circle(a[0], a[1], a[2])
+		case OpClosePath:
+			c.ClosePath()
+		case OpPathWinding:
+			c.PathWinding(Winding(a[0]))
+		case OpFill:
+			c.Fill()
+		case OpStroke:
+			c.Stroke()
+		case OpSetFontSize:
+			c.SetFontSize(a[0])
+		case OpSetTextLetterSpacing:
+			c.SetTextLetterSpacing(a[0])
+		case OpSetTextLineHeight:
+			c.SetTextLineHeight(a[0])
+		case OpSetTextAlign:
+			c.SetTextAlign(Align(a[0]))
+		case OpSetFontFaceID:
+			c.SetFontFaceID(int(a[0]))
+		case OpSetFontFace:
+			c.SetFontFace(op.Text)
+		case OpText:
+			c.Text(a[0], a[1], op.Text)
+		case OpBeginLayer:
+			c.BeginLayer(a[0], a[1], a[2], a[3], a[4])
+		case OpEndLayer:
+			c.EndLayer()
+		case OpClipPath:
+			c.ClipPath()
+		case OpResetClip:
+			c.ResetClip()
+		case OpSetMask:
+			c.SetMask(imageHandle(a[0]))
+		case OpResetMask:
+			c.ResetMask()
+		case OpSetGlobalCompositeOperation:
+			c.SetGlobalCompositeOperation(CompositeOperation(a[0]))
+		case OpSetGlobalCompositeBlendFunc:
+			c.SetGlobalCompositeBlendFunc(BlendFactor(a[0]), BlendFactor(a[1]))
+		case OpSetGlobalCompositeBlendFuncSeparate:
+			c.SetGlobalCompositeBlendFuncSeparate(BlendFactor(a[0]), BlendFactor(a[1]), BlendFactor(a[2]), BlendFactor(a[3]))
+		case OpSetBlendMode:
+			c.SetBlendMode(BlendMode(a[0]))
+		case OpSetLineDash:
+			c.SetLineDash(a)
+		case OpSetLineDashOffset:
+			c.SetLineDashOffset(a[0])
+		case OpSetFillRule:
+			c.SetFillRule(FillRule(a[0]))
+		}
+	}
+	return created, nil
+}
+
+func colorToArgs(c color.Color) []float32 {
+	rgba := colorToArray(c)
+	return rgba[:]
+}
+
+func argsToColor(args []float32) color.Color {
+	return color.RGBA64{
+		R: uint16(args[0]*0xffff + 0.5),
+		G: uint16(args[1]*0xffff + 0.5),
+		B: uint16(args[2]*0xffff + 0.5),
+		A: uint16(args[3]*0xffff + 0.5),
+	}
+}
+
+func paintToArgs(p *Paint) []float32 {
+	args := make([]float32, 0, 20)
+	args = append(args, p.Xform[:]...)
+	args = append(args, p.Extent[:]...)
+	args = append(args, p.Radius, p.Feather)
+	args = append(args, colorToArgs(p.InnerColor)...)
+	args = append(args, colorToArgs(p.OuterColor)...)
+	args = append(args, float32(p.Image))
+	if p.Conic {
+		args = append(args, 1)
+	} else {
+		args = append(args, 0)
+	}
+	// Each gradient stop follows as the offset and the color.
+	for _, stop := range p.Stops {
+		args = append(args, stop.Offset)
+		args = append(args, colorToArgs(stop.Color)...)
+	}
+	return args
+}
+
+func argsToPaint(args []float32) Paint {
+	var p Paint
+	copy(p.Xform[:], args[0:6])
+	copy(p.Extent[:], args[6:8])
+	p.Radius = args[8]
+	p.Feather = args[9]
+	p.InnerColor = argsToColor(args[10:14])
+	p.OuterColor = argsToColor(args[14:18])
+	p.Image = int(args[18])
+	p.Conic = args[19] != 0
+	for i := 20; i+5 <= len(args); i += 5 {
+		p.Stops = append(p.Stops, GradientStop{Offset: args[i], Color: argsToColor(args[i+1 : i+5])})
+	}
+	return p
+}
+
+func imageToRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,168 @@
+package nanovgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func drawRecorderSample(ctx *Context) {
+	ctx.Save()
+	ctx.Translate(4, 4)
+	ctx.BeginPath()
+	ctx.RoundedRect(0, 0, 30, 20, 4)
+	ctx.SetFillPaint(LinearGradient(0, 0, 30, 0, red, blue))
+	ctx.Fill()
+	ctx.Restore()
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{G: 255, A: 255})
+	handle := ctx.CreateImage(img)
+	ctx.BeginPath()
+	ctx.Circle(48, 48, 10)
+	ctx.SetFillPaint(ImagePattern(38, 38, 4, 4, 0, handle, 1.0))
+	ctx.Fill()
+
+	ctx.BeginPath()
+	ctx.MoveTo(4, 60)
+	ctx.ArcTo(30, 60, 30, 40, 8)
+	ctx.SetStrokeColor(color.NRGBA{R: 255, A: 128})
+	ctx.SetStrokeWidth(3)
+	ctx.Stroke()
+}
+
+func TestRecorderReplay(t *testing.T) {
+	ctx, expected := newTestImageContext(t, 64, 64, AntiAlias)
+	recorder := &Recorder{}
+	ctx.SetRecorder(recorder)
+	drawRecorderSample(ctx)
+	ctx.EndFrame()
+
+	data, err := json.Marshal(recorder.DisplayList())
+	if err != nil {
+		t.Fatalf("json.Marshal() failed: %v", err)
+	}
+	var list DisplayList
+	if err := json.Unmarshal(data, &list); err != nil {
+		t.Fatalf("json.Unmarshal() failed: %v", err)
+	}
+
+	replayCtx, actual := newTestImageContext(t, 64, 64, AntiAlias)
+	images, err := list.Replay(replayCtx)
+	if err != nil {
+		t.Fatalf("Replay() failed: %v", err)
+	}
+	replayCtx.EndFrame()
+	if len(images) != 1 {
+		t.Errorf("Replay() should create 1 image, but %d", len(images))
+	}
+	if !bytes.Equal(expected.Pix, actual.Pix) {
+		t.Error("replayed image should be same as the original")
+	}
+}
+
+func TestRecorderNestedOps(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+	recorder := &Recorder{}
+	ctx.SetRecorder(recorder)
+	ctx.Block(func() {
+		ctx.BeginPath()
+		ctx.Circle(8, 8, 4)
+		ctx.RoundedRect(0, 0, 8, 8, 0)
+		ctx.SetFillImage(0, 0, 1, 1, 0, 0, 1)
+	})
+	ctx.SetRecorder(nil)
+	ctx.Fill()
+
+	expected := []DrawOpKind{OpSave, OpBeginPath, OpCircle, OpRoundedRect, OpSetFillPaint, OpRestore}
+	list := recorder.DisplayList()
+	if len(list) != len(expected) {
+		t.Fatalf("recorder should have %d ops, but %v", len(expected), list)
+	}
+	for i, op := range list {
+		if op.Kind != expected[i] {
+			t.Errorf("op %d should be %s, but %s", i, expected[i], op.Kind)
+		}
+	}
+	recorder.Clear()
+	if len(recorder.DisplayList()) != 0 {
+		t.Error("Clear() should drop the recorded ops")
+	}
+}
+
+func TestReplayInvalidOps(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	cases := map[string]DrawOp{
+		"short args":       {Kind: OpRect, Args: []float32{1}},
+		"long args":        {Kind: OpBeginPath, Args: []float32{1}},
+		"short paint":      {Kind: OpSetFillPaint, Args: make([]float32, 19)},
+		"broken stop":      {Kind: OpSetStrokePaint, Args: make([]float32, 22)},
+		"no image":         {Kind: OpCreateImage, Args: []float32{1}},
+		"short pixels":     {Kind: OpCreateImage, Args: []float32{1}, Image: &image.RGBA{Pix: img.Pix[:8], Stride: 8, Rect: img.Rect}},
+		"negative kind":    {Kind: -1},
+		"unknown kind":     {Kind: DrawOpKind(len(drawOpNames))},
+		"no color":         {Kind: OpSetFillColor},
+		"no text position": {Kind: OpText, Text: "text"},
+	}
+	for name, op := range cases {
+		ctx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+		list := DisplayList{{Kind: OpCreateImage, Args: []float32{1}, Image: img}, op, {Kind: OpFill}}
+		images, err := list.Replay(ctx)
+		if err == nil {
+			t.Errorf("%s: Replay() should fail", name)
+		}
+		if len(images) != 1 {
+			t.Errorf("%s: Replay() should return the image created before the error, but %v", name, images)
+		}
+	}
+}
+
+func TestDrawOpArgCounts(t *testing.T) {
+	if len(drawOpArgCounts) != len(drawOpNames) {
+		t.Errorf("every kind should have the number of the args, but %d for %d kinds", len(drawOpArgCounts), len(drawOpNames))
+	}
+	paint := LinearGradientStops(0, 0, 10, 0, testStops)
+	op := DrawOp{Kind: OpSetFillPaint, Args: paintToArgs(&paint)}
+	if err := op.validate(); err != nil {
+		t.Errorf("the paint with the stops should be valid, but %v", err)
+	}
+}
+
+func TestRecorderFramebufferImage(t *testing.T) {
+	ctx, _ := newTestImageContext(t, 16, 16, AntiAlias)
+	ctx.EndFrame()
+	recorder := &Recorder{}
+	ctx.SetRecorder(recorder)
+	fb, err := ctx.CreateFramebuffer(16, 16, 0)
+	if err != nil {
+		t.Fatalf("CreateFramebuffer() failed: %v", err)
+	}
+	ctx.BeginFrame(16, 16, 1.0)
+	ctx.SetMask(fb.Image())
+	ctx.BeginPath()
+	ctx.Rect(0, 0, 16, 16)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	// Framebuffers are not recorded, so the mask keeps the handle of the framebuffer image.
+	list := recorder.DisplayList()
+	expected := []DrawOpKind{OpSave, OpReset, OpSetMask, OpBeginPath, OpRect, OpFill}
+	if len(list) != len(expected) {
+		t.Fatalf("recorder should have %d ops, but %v", len(expected), list)
+	}
+	if list[2].Args[0] != float32(fb.Image()) {
+		t.Errorf("the mask should be the framebuffer image %d, but %v", fb.Image(), list[2].Args)
+	}
+	ctx.SetRecorder(nil)
+	ctx.BeginFrame(16, 16, 1.0)
+	images, err := list.Replay(ctx)
+	if err != nil || len(images) != 0 {
+		t.Errorf("Replay() on the recording context should use the framebuffer image, but %v %v", images, err)
+	}
+	if ctx.getState().mask != fb.Image() {
+		t.Errorf("the replayed mask should be %d, but %d", fb.Image(), ctx.getState().mask)
+	}
+	ctx.EndFrame()
+}
@@ -0,0 +1,145 @@
+package nanovgo
+
+import (
+	"image/color"
+	"testing"
+)
+
+type testRenderer struct {
+	textures int
+	fills    [][]Path
+	strokes  [][]Path
+	flushed  bool
+}
+
+func (r *testRenderer) EdgeAntiAlias() bool { return true }
+func (r *testRenderer) Create() error       { return nil }
+func (r *testRenderer) CreateTexture(texType TextureType, w, h int, data []byte) int {
+	r.textures++
+	return r.textures
+}
+func (r *testRenderer) DeleteTexture(image int) error                          { return nil }
+func (r *testRenderer) UpdateTexture(image, x, y, w, h int, data []byte) error { return nil }
+func (r *testRenderer) GetTextureSize(image int) (int, int, error)             { return 0, 0, nil }
+func (r *testRenderer) Viewport(width, height int)                             {}
+func (r *testRenderer) Cancel()                                                {}
+func (r *testRenderer) Flush()                                                 { r.flushed = true }
+func (r *testRenderer) Fill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []Path) {
+	r.fills = append(r.fills, append([]Path(nil), paths...))
+}
+func (r *testRenderer) Stroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []Path) {
+	r.strokes = append(r.strokes, append([]Path(nil), paths...))
+}
+func (r *testRenderer) Triangles(paint *Paint, scissor *Scissor, vertexes []Vertex)     {}
+func (r *testRenderer) TriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {}
+func (r *testRenderer) Delete()                                                         {}
+
+func TestNewContextWithRenderer(t *testing.T) {
+	r := &testRenderer{}
+	ctx, err := NewContextWithRenderer(r)
+	if err != nil {
+		t.Fatalf("NewContextWithRenderer() failed: %v", err)
+	}
+	if r.textures != 1 {
+		t.Errorf("font texture should be created, but %d textures", r.textures)
+	}
+
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 20, 20)
+	ctx.SetFillColor(color.RGBA{R: 255, A: 255})
+	ctx.Fill()
+	ctx.Stroke()
+	ctx.EndFrame()
+
+	if len(r.fills) != 1 || len(r.fills[0]) != 1 {
+		t.Fatalf("Fill() should pass one path, but %v", r.fills)
+	}
+	if !r.fills[0][0].Convex {
+		t.Error("rectangle should be convex")
+	}
+	if len(r.fills[0][0].Fills) == 0 || len(r.fills[0][0].Strokes) == 0 {
+		t.Error("fill path should have fill and fringe vertexes")
+	}
+	if len(r.strokes) != 1 || len(r.strokes[0][0].Strokes) == 0 {
+		t.Errorf("Stroke() should pass stroke vertexes, but %v", r.strokes)
+	}
+	if !r.flushed {
+		t.Error("EndFrame() should flush the renderer")
+	}
+}
+
+// extensionRenderer implements the renderer extensions by the exported methods like renderers outside of the package.
+type extensionRenderer struct {
+	testRenderer
+	framebuffer int
+	clip        *Clip
+	mask        int
+	composite   CompositeOperationState
+	blendMode   BlendMode
+	fillRule    FillRule
+}
+
+func (r *extensionRenderer) CreateFramebuffer(w, h int, flags ImageFlags) (int, int, error) {
+	return 1, r.CreateTexture(TextureRGBA, w, h, nil), nil
+}
+func (r *extensionRenderer) BindFramebuffer(fb int) error                     { r.framebuffer = fb; return nil }
+func (r *extensionRenderer) DeleteFramebuffer(fb int) error                   { return nil }
+func (r *extensionRenderer) SetClip(clip *Clip)                               { r.clip = clip }
+func (r *extensionRenderer) SetMask(image int)                                { r.mask = image }
+func (r *extensionRenderer) SetCompositeOperation(op CompositeOperationState) { r.composite = op }
+func (r *extensionRenderer) SetBlendMode(mode BlendMode)                      { r.blendMode = mode }
+func (r *extensionRenderer) SetFillRule(rule FillRule)                        { r.fillRule = rule }
+
+func TestFramebufferRendererExtension(t *testing.T) {
+	r := &extensionRenderer{}
+	ctx, err := NewContextWithRenderer(r)
+	if err != nil {
+		t.Fatalf("NewContextWithRenderer() failed: %v", err)
+	}
+	fb, err := ctx.CreateFramebuffer(16, 16, 0)
+	if err != nil {
+		t.Fatalf("CreateFramebuffer() failed: %v", err)
+	}
+	ctx.BindFramebuffer(fb)
+	if r.framebuffer != 1 {
+		t.Errorf("the framebuffer should be bound, but %d", r.framebuffer)
+	}
+	ctx.BindFramebuffer(nil)
+	if r.framebuffer != 0 {
+		t.Errorf("the default target should be bound, but %d", r.framebuffer)
+	}
+}
+
+func TestRendererExtensions(t *testing.T) {
+	r := &extensionRenderer{}
+	ctx, err := NewContextWithRenderer(r)
+	if err != nil {
+		t.Fatalf("NewContextWithRenderer() failed: %v", err)
+	}
+	ctx.BeginFrame(100, 100, 1.0)
+	ctx.BeginPath()
+	ctx.Rect(10, 10, 20, 20)
+	ctx.SetFillRule(EvenOdd)
+	ctx.ClipPath()
+	ctx.SetMask(3)
+	ctx.SetGlobalCompositeOperation(Lighter)
+	ctx.Fill()
+	ctx.EndFrame()
+
+	if r.clip == nil || len(r.clip.Paths) != 1 || r.clip.FillRule != EvenOdd {
+		t.Errorf("the clip should be passed, but %v", r.clip)
+	}
+	if r.mask != 3 || r.fillRule != EvenOdd {
+		t.Errorf("the mask and the fill rule should be passed, but %d %d", r.mask, r.fillRule)
+	}
+	if r.composite != (CompositeOperationState{SrcRGB: One, DstRGB: One, SrcAlpha: One, DstAlpha: One}) {
+		t.Errorf("the composite operation should be passed, but %v", r.composite)
+	}
+
+	ctx.SetBlendMode(BlendScreen)
+	ctx.Stroke()
+	if r.blendMode != BlendScreen || !r.composite.isSourceOver() {
+		t.Error("the blend mode should be passed with source-over")
+	}
+}
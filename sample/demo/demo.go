@@ -693,7 +693,7 @@ func drawThumbnails(ctx *nanovgo.Context, x, y, w, h float32, images []int, t fl
 }
 
 func drawColorWheel(ctx *nanovgo.Context, x, y, w, h, t float32) {
-	var r0, r1, ax, ay, bx, by, aeps, r float32
+	var r0, r1, ax, ay, bx, by, r float32
 	hue := sinF(t * 0.12)
 
 	ctx.Save()
@@ -711,23 +711,18 @@ func drawColorWheel(ctx *nanovgo.Context, x, y, w, h, t float32) {
 		r1 = h*0.5 - 5.0
 	}
 	r0 = r1 - 20.0
-	aeps = 0.5 / r1 // half a pixel arc length in radians (2pi cancels out).
 
-	for i := 0; i < 6; i++ {
-		a0 := float32(i)/6.0*nanovgo.PI*2.0 - aeps
-		a1 := float32(i+1.0)/6.0*nanovgo.PI*2.0 + aeps
-		ctx.BeginPath()
-		ctx.Arc(cx, cy, r0, a0, a1, nanovgo.Clockwise)
-		ctx.Arc(cx, cy, r1, a1, a0, nanovgo.CounterClockwise)
-		ctx.ClosePath()
-		ax = cx + cosF(a0)*(r0+r1)*0.5
-		ay = cy + sinF(a0)*(r0+r1)*0.5
-		bx = cx + cosF(a1)*(r0+r1)*0.5
-		by = cy + sinF(a1)*(r0+r1)*0.5
-		paint := nanovgo.LinearGradient(ax, ay, bx, by, nanovgo.HSLA(a0/(nanovgo.PI*2), 1.0, 0.55, 255), nanovgo.HSLA(a1/(nanovgo.PI*2), 1.0, 0.55, 255))
-		ctx.SetFillPaint(paint)
-		ctx.Fill()
+	stops := make(nanovgo.GradientStops, 7)
+	for i := range stops {
+		offset := float32(i) / 6.0
+		stops[i] = nanovgo.GradientStop{Offset: offset, Color: nanovgo.HSLA(offset, 1.0, 0.55, 255)}
 	}
+	ctx.BeginPath()
+	ctx.Circle(cx, cy, r1)
+	ctx.Circle(cx, cy, r0)
+	ctx.PathWinding(nanovgo.Hole)
+	ctx.SetFillPaint(nanovgo.ConicGradient(cx, cy, 0, stops))
+	ctx.Fill()
 
 	ctx.BeginPath()
 	ctx.Circle(cx, cy, r0-0.5)
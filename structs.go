@@ -6,6 +6,115 @@ import (
 	"github.com/shibukawa/nanovgo/fontstashmini"
 )
 
+// Renderer is implemented by the rendering backends that draw the tessellated paths.
+// Context does path tessellation and text layout, and passes the resulting vertexes to Renderer.
+// Pass an implementation to NewContextWithRenderer() to draw with your own engine.
+type Renderer interface {
+	// EdgeAntiAlias returns true if Context should generate anti-aliasing fringes.
+	EdgeAntiAlias() bool
+	// Create is called once when the Context is created.
+	Create() error
+	// CreateTexture creates a texture and returns its image handle. data may be nil.
+	CreateTexture(texType TextureType, w, h int, data []byte) int
+	// DeleteTexture deletes the texture.
+	DeleteTexture(image int) error
+	// UpdateTexture updates the region of the texture. data contains whole texture image.
+	UpdateTexture(image, x, y, w, h int, data []byte) error
+	// GetTextureSize returns the size of the texture.
+	GetTextureSize(image int) (int, int, error)
+	// Viewport is called from Context.BeginFrame().
+	Viewport(width, height int)
+	// Cancel is called from Context.CancelFrame() and discards queued draw calls.
+	Cancel()
+	// Flush is called from Context.EndFrame() and draws queued draw calls.
+	Flush()
+	// Fill draws the filled paths. Each path has fill vertexes as triangle fan and fringe vertexes as triangle strip.
+	Fill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []Path)
+	// Stroke draws the stroke vertexes of paths as triangle strips.
+	Stroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []Path)
+	// Triangles draws the triangles. It is used for text.
+	Triangles(paint *Paint, scissor *Scissor, vertexes []Vertex)
+	// TriangleStrip draws the triangle strip. It is used for text.
+	TriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex)
+	// Delete is called from Context.Delete().
+	Delete()
+}
+
+// Renderer extensions
+//
+// Renderers can implement the following interfaces in addition to Renderer to support more features.
+// Context finds them by type assertions. The features are ignored by the renderers that don't implement them,
+// except that Context.BeginLayer() applies the alpha to each shape without FramebufferRenderer.
+// The render state setters are called with the state of the current draw call before Fill(), Stroke(),
+// Triangles() and TriangleStrip() are called, so renderers keep the values with the queued draw calls.
+
+// FramebufferRenderer is implemented by the renderers that can draw into images.
+// It is used by Context.CreateFramebuffer() and Context.BeginLayer().
+type FramebufferRenderer interface {
+	// CreateFramebuffer returns the framebuffer handle and the image handle of its color buffer.
+	// The framebuffer has the stencil buffer, and it is cleared to transparent.
+	CreateFramebuffer(w, h int, flags ImageFlags) (int, int, error)
+	// BindFramebuffer switches the drawing target. 0 means the default target of the renderer.
+	BindFramebuffer(fb int) error
+	// DeleteFramebuffer deletes the framebuffer and its image.
+	DeleteFramebuffer(fb int) error
+}
+
+// ClipRenderer is implemented by the renderers that support Context.ClipPath().
+type ClipRenderer interface {
+	// SetClip sets the clip of following draw calls. nil disables clipping.
+	SetClip(clip *Clip)
+}
+
+// MaskRenderer is implemented by the renderers that support Context.SetMask().
+type MaskRenderer interface {
+	// SetMask sets the image that modulates the alpha of following draw calls. 0 disables the mask.
+	SetMask(image int)
+}
+
+// CompositeRenderer is implemented by the renderers that support Context.SetGlobalCompositeOperation().
+type CompositeRenderer interface {
+	// SetCompositeOperation sets the blend factors of following draw calls.
+	SetCompositeOperation(op CompositeOperationState)
+}
+
+// BlendModeRenderer is implemented by the renderers that support Context.SetBlendMode().
+type BlendModeRenderer interface {
+	// SetBlendMode sets the blend mode of following draw calls.
+	SetBlendMode(mode BlendMode)
+}
+
+// FillRuleRenderer is implemented by the renderers that support Context.SetFillRule().
+type FillRuleRenderer interface {
+	// SetFillRule sets the fill rule of following Fill() calls. The clips have their own fill rules.
+	SetFillRule(rule FillRule)
+}
+
+// applyRenderState passes the render state of the current state to the renderer by the extensions.
+func (c *Context) applyRenderState() {
+	state := c.getState()
+	if renderer, ok := c.renderer.(ClipRenderer); ok {
+		renderer.SetClip(state.clip)
+	}
+	if renderer, ok := c.renderer.(MaskRenderer); ok {
+		renderer.SetMask(state.mask)
+	}
+	if renderer, ok := c.renderer.(CompositeRenderer); ok {
+		// Blend modes are composited by source-over.
+		if state.blendMode != BlendNormal {
+			renderer.SetCompositeOperation(compositeOperationState(SourceOver))
+		} else {
+			renderer.SetCompositeOperation(state.composite)
+		}
+	}
+	if renderer, ok := c.renderer.(BlendModeRenderer); ok {
+		renderer.SetBlendMode(state.blendMode)
+	}
+	if renderer, ok := c.renderer.(FillRuleRenderer); ok {
+		renderer.SetFillRule(state.fillRule)
+	}
+}
+
 type nvgPoint struct {
 	x, y     float32
 	dx, dy   float32
@@ -14,38 +123,55 @@ type nvgPoint struct {
 	flags    nvgPointFlags
 }
 
-type nvgVertex struct {
-	x, y, u, v float32
+// Vertex is a tessellated vertex passed to Renderer. U and V are texture coordinates
+// or anti-aliasing parameters.
+type Vertex struct {
+	X, Y, U, V float32
 }
 
-func (vtx *nvgVertex) set(x, y, u, v float32) {
-	vtx.x = x
-	vtx.y = y
-	vtx.u = u
-	vtx.v = v
+func (vtx *Vertex) set(x, y, u, v float32) {
+	vtx.X = x
+	vtx.Y = y
+	vtx.U = u
+	vtx.V = v
 }
 
-type nvgPath struct {
+// Path is a tessellated path passed to Renderer.
+type Path struct {
 	first   int
 	count   int
 	closed  bool
 	nBevel  int
-	fills   []nvgVertex
-	strokes []nvgVertex
+	Fills   []Vertex
+	Strokes []Vertex
 	winding Winding
-	convex  bool
+	Convex  bool
+	flipped bool // the points are reversed to enforce the winding
 }
 
-type nvgScissor struct {
-	xform  TransformMatrix
-	extent [2]float32
+// Scissor is a scissor rectangle passed to Renderer. Extent is the half size of the rectangle
+// and Xform transforms from the center of the rectangle to the canvas.
+type Scissor struct {
+	Xform  TransformMatrix
+	Extent [2]float32
 }
 
 type nvgState struct {
 	fill, stroke  Paint
 	strokeWidth   float32
+	miterLimit    float32
+	lineJoin      LineCap
+	lineCap       LineCap
+	lineDash      []float32
+	dashOffset    float32
+	alpha         float32
 	xform         TransformMatrix
-	scissor       nvgScissor
+	scissor       Scissor
+	clip          *Clip
+	mask          int
+	composite     CompositeOperationState
+	blendMode     BlendMode
+	fillRule      FillRule
 	fontSize      float32
 	letterSpacing float32
 	lineHeight    float32
@@ -57,12 +183,23 @@ func (s *nvgState) reset() {
 	s.fill.setPaintColor(color.NRGBA{R: 255, G: 255, B: 255, A: 255})
 	s.stroke.setPaintColor(color.NRGBA{A: 255})
 	s.strokeWidth = 1.0
+	s.miterLimit = 10.0
+	s.lineCap = Butt
+	s.lineJoin = Miter
+	s.lineDash = nil
+	s.dashOffset = 0.0
+	s.alpha = 1.0
 	s.xform = IdentityMatrix()
-	s.scissor.xform = IdentityMatrix()
-	s.scissor.xform[0] = 0.0
-	s.scissor.xform[3] = 0.0
-	s.scissor.extent[0] = -1.0
-	s.scissor.extent[1] = -1.0
+	s.scissor.Xform = IdentityMatrix()
+	s.scissor.Xform[0] = 0.0
+	s.scissor.Xform[3] = 0.0
+	s.scissor.Extent[0] = -1.0
+	s.scissor.Extent[1] = -1.0
+	s.clip = nil
+	s.mask = 0
+	s.composite = compositeOperationState(SourceOver)
+	s.blendMode = BlendNormal
+	s.fillRule = NonZero
 
 	s.fontSize = 16.0
 	s.letterSpacing = 0.0
@@ -77,14 +214,14 @@ func (s *nvgState) getFontScale() float32 {
 
 type nvgPathCache struct {
 	points   []nvgPoint
-	paths    []nvgPath
-	vertexes []nvgVertex
+	paths    []Path
+	vertexes []Vertex
 	bounds   [4]float32
 }
 
-func (c *nvgPathCache) allocVertexes(n int) []nvgVertex {
+func (c *nvgPathCache) allocVertexes(n int) []Vertex {
 	offset := len(c.vertexes)
-	c.vertexes = append(c.vertexes, make([]nvgVertex, n)...)
+	c.vertexes = append(c.vertexes, make([]Vertex, n)...)
 	return c.vertexes[offset:]
 }
 
@@ -94,7 +231,7 @@ func (c *nvgPathCache) clearPathCache() {
 	c.vertexes = c.vertexes[:0]
 }
 
-func (c *nvgPathCache) lastPath() *nvgPath {
+func (c *nvgPathCache) lastPath() *Path {
 	if len(c.paths) > 0 {
 		return &c.paths[len(c.paths)-1]
 	}
@@ -102,7 +239,7 @@ func (c *nvgPathCache) lastPath() *nvgPath {
 }
 
 func (c *nvgPathCache) addPath() {
-	c.paths = append(c.paths, nvgPath{first: len(c.points), winding: Solid})
+	c.paths = append(c.paths, Path{first: len(c.points), winding: Solid})
 }
 
 func (c *nvgPathCache) lastPoint() *nvgPoint {
@@ -114,6 +251,10 @@ func (c *nvgPathCache) lastPoint() *nvgPoint {
 
 func (c *nvgPathCache) addPoint(x, y float32, flags nvgPointFlags, distTol float32) {
 	path := c.lastPath()
+	if path == nil {
+		// The points before the first MoveTo() are ignored.
+		return
+	}
 
 	if path.count > 0 && len(c.points) > 0 {
 		lastPoint := c.lastPoint()
@@ -180,6 +321,135 @@ func (c *nvgPathCache) tesselateBezier(x1, y1, x2, y2, x3, y3, x4, y4 float32, l
 	c.tesselateBezier(x1234, y1234, x234, y234, x34, y34, x4, y4, level+1, flags, tessTol, distTol)
 }
 
+// dashPaths splits the flattened paths into the open paths of the dashes. The lengths of the dashes and the gaps
+// alternate in the pattern, and the offset shifts the start of the pattern. The pattern continues over the corners
+// and the paths, and the dashes over the start of closed paths are joined.
+func (c *nvgPathCache) dashPaths(pattern []float32, offset, distTol float32) {
+	var total float32
+	for _, length := range pattern {
+		total += length
+	}
+	// Patterns shorter than the tolerance can't be seen, so they are drawn solid.
+	if total <= distTol {
+		return
+	}
+	// Find the dash or the gap at the start.
+	offset -= float32(int(offset/total)) * total
+	if offset < 0.0 {
+		offset += total
+	}
+	index := 0
+	for offset > pattern[index] || (offset == pattern[index] && offset > 0.0) {
+		offset -= pattern[index]
+		index = (index + 1) % len(pattern)
+	}
+	remain := pattern[index] - offset
+	on := index%2 == 0
+
+	points, paths := c.points, c.paths
+	c.points = make([]nvgPoint, 0, len(points))
+	c.paths = make([]Path, 0, len(paths))
+	for _, path := range paths {
+		if path.count < 2 {
+			continue
+		}
+		pathPoints := points[path.first : path.first+path.count]
+		if path.flipped {
+			// Dashes start from the first point of the original path.
+			pathPoints = reversePoints(pathPoints)
+		}
+		first := len(c.paths)
+		startOn, toggled := on, false
+		if on {
+			c.addPath()
+			c.addPoint(pathPoints[0].x, pathPoints[0].y, nvgPtCORNER, distTol)
+		}
+		segments := path.count - 1
+		if path.closed {
+			segments = path.count
+		}
+		for i := 0; i < segments; i++ {
+			p0 := &pathPoints[i]
+			p1 := &pathPoints[(i+1)%path.count]
+			var pos float32
+			for p0.len-pos > remain {
+				pos += remain
+				x, y := p0.x+p0.dx*pos, p0.y+p0.dy*pos
+				if on {
+					c.endDash(x, y, p0.dx, p0.dy, distTol)
+				} else {
+					c.addPath()
+					c.addPoint(x, y, nvgPtCORNER, distTol)
+				}
+				on, toggled = !on, true
+				index = (index + 1) % len(pattern)
+				remain = pattern[index]
+			}
+			remain -= p0.len - pos
+			if on {
+				c.addPoint(p1.x, p1.y, p1.flags, distTol)
+			}
+		}
+		if !on {
+			continue
+		}
+		last := c.lastPath()
+		switch {
+		case path.closed && startOn && !toggled:
+			// The dash covers the whole path, so the repeated start point is removed.
+			last.count--
+			last.closed = true
+			c.points = c.points[:len(c.points)-1]
+		case path.closed && startOn:
+			head := c.paths[first]
+			headPoints := append([]nvgPoint(nil), c.points[head.first:head.first+head.count]...)
+			for _, p := range headPoints[1:] {
+				c.addPoint(p.x, p.y, p.flags, distTol)
+			}
+			c.paths = append(c.paths[:first], c.paths[first+1:]...)
+		case last.count < 2:
+			c.points = c.points[:last.first]
+			c.paths = c.paths[:len(c.paths)-1]
+		}
+	}
+
+	// Calculate the direction and length of the segments of the dashes.
+	for i := range c.paths {
+		path := &c.paths[i]
+		pathPoints := c.points[path.first : path.first+path.count]
+		for j := range pathPoints {
+			p0 := &pathPoints[j]
+			p1 := &pathPoints[(j+1)%path.count]
+			p0.len, p0.dx, p0.dy = normalize(p1.x-p0.x, p1.y-p0.y)
+		}
+	}
+}
+
+// reversePoints returns the copy of the points in the reversed order with the directions and the lengths of
+// the segments.
+func reversePoints(points []nvgPoint) []nvgPoint {
+	n := len(points)
+	reversed := make([]nvgPoint, n)
+	for i := range points {
+		reversed[i] = points[n-1-i]
+	}
+	for i := range reversed {
+		p0 := &reversed[i]
+		p1 := &reversed[(i+1)%n]
+		p0.len, p0.dx, p0.dy = normalize(p1.x-p0.x, p1.y-p0.y)
+	}
+	return reversed
+}
+
+// endDash ends the dash at (x,y). Zero length dashes keep the direction (dx,dy) for the caps.
+func (c *nvgPathCache) endDash(x, y, dx, dy, distTol float32) {
+	c.addPoint(x, y, nvgPtCORNER, distTol)
+	if path := c.lastPath(); path.count == 1 {
+		c.points = append(c.points, nvgPoint{x: x + dx*distTol*2, y: y + dy*distTol*2, flags: nvgPtCORNER})
+		path.count++
+	}
+}
+
 func (c *nvgPathCache) calculateJoins(w float32, lineJoin LineCap, miterLimit float32) {
 	var iw float32
 	if w > 0.0 {
@@ -248,7 +518,7 @@ func (c *nvgPathCache) calculateJoins(w float32, lineJoin LineCap, miterLimit fl
 				p1 = &points[p1Index]
 			}
 		}
-		path.convex = nLeft == path.count
+		path.Convex = nLeft == path.count
 	}
 }
 
@@ -283,7 +553,12 @@ func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterL
 		path := &c.paths[i]
 		points := c.points[path.first:]
 
-		path.fills = path.fills[:0]
+		path.Fills = path.Fills[:0]
+		if path.count < 2 {
+			// Single points have no direction to stroke.
+			path.Strokes = path.Strokes[:0]
+			continue
+		}
 
 		// Calculate fringe or stroke
 		index := 0
@@ -338,8 +613,8 @@ func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterL
 		}
 
 		if path.closed {
-			(&dst[index]).set(dst[0].x, dst[0].y, 0, 1)
-			(&dst[index+1]).set(dst[1].x, dst[1].y, 1, 1)
+			(&dst[index]).set(dst[0].X, dst[0].Y, 0, 1)
+			(&dst[index+1]).set(dst[1].X, dst[1].Y, 1, 1)
 			index += 2
 		} else {
 			dx := p1.x - p0.x
@@ -355,7 +630,7 @@ func (c *nvgPathCache) expandStroke(w float32, lineCap, lineJoin LineCap, miterL
 			}
 		}
 
-		path.strokes = dst[0:index]
+		path.Strokes = dst[0:index]
 		dst = dst[index:]
 	}
 }
@@ -377,11 +652,17 @@ func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fring
 
 	dst := c.allocVertexes(countVertex)
 
-	convex := len(c.paths) == 1 && c.paths[0].convex
+	convex := len(c.paths) == 1 && c.paths[0].Convex
 
 	for i := 0; i < len(c.paths); i++ {
 		path := &c.paths[i]
 		points := c.points[path.first:]
+		if path.count < 2 {
+			// Single points have no area to fill.
+			path.Fills = path.Fills[:0]
+			path.Strokes = path.Strokes[:0]
+			continue
+		}
 
 		// Calculate shape vertices.
 		wOff := 0.5 * aa
@@ -431,7 +712,7 @@ func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fring
 				index++
 			}
 		}
-		path.fills = dst[0:index]
+		path.Fills = dst[0:index]
 		dst = dst[index:]
 
 		// Calculate fringe
@@ -458,7 +739,7 @@ func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fring
 					index = bevelJoin(dst, index, p0, p1, lw, rw, lu, ru, fringeWidth)
 				} else {
 					(&dst[index]).set(p1.x+(p1.dmx*lw), p1.y+(p1.dmy*lw), lu, 1)
-					(&dst[index+1]).set(p1.x+(p1.dmx*lw), p1.y+(p1.dmy*lw), lu, 1)
+					(&dst[index+1]).set(p1.x-(p1.dmx*rw), p1.y-(p1.dmy*rw), ru, 1)
 					index += 2
 				}
 				p1Index++
@@ -468,14 +749,14 @@ func (c *nvgPathCache) expandFill(w float32, lineJoin LineCap, miterLimit, fring
 				}
 			}
 			// Loop it
-			(&dst[index]).set(dst[0].x, dst[0].y, lu, 1)
-			(&dst[index+1]).set(dst[1].x, dst[1].y, ru, 1)
+			(&dst[index]).set(dst[0].X, dst[0].Y, lu, 1)
+			(&dst[index+1]).set(dst[1].X, dst[1].Y, ru, 1)
 			index += 2
 
-			path.strokes = dst[0:index]
+			path.Strokes = dst[0:index]
 			dst = dst[index:]
 		} else {
-			path.strokes = path.strokes[:0]
+			path.Strokes = path.Strokes[:0]
 		}
 	}
 }
@@ -0,0 +1,539 @@
+package nanovgo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/shibukawa/nanovgo/fontstashmini/truetype"
+)
+
+// NewSVGContext makes new NanoVGo context that writes each frame as SVG document.
+// The document is written to w when Context.EndFrame() is called. Paths keep their curves,
+// gradients become <linearGradient>/<radialGradient>, scissors become <clipPath>,
+// images are embedded as PNG data URIs, and text becomes <text> elements with the embedded font.
+// Box and conic gradients don't have SVG equivalents, so they are embedded as images.
+func NewSVGContext(w io.Writer) (*Context, error) {
+	return NewContextWithRenderer(&svgContext{
+		w:          w,
+		imageURIs:  make(map[int]string),
+		fontScales: make(map[int]float32),
+	})
+}
+
+type svgContext struct {
+	imageTextures
+	w      io.Writer
+	buf    bytes.Buffer
+	nextID int
+
+	imageURIs     map[int]string
+	fontScales    map[int]float32
+	embeddedFonts map[int]bool
+	clip          *Clip
+	clipIDs       map[*Clip]string
+	mask          int
+	maskIDs       map[int]string
+	blendMode     BlendMode
+	fillRule      FillRule
+	width, height int
+}
+
+func (c *svgContext) EdgeAntiAlias() bool {
+	return false
+}
+
+func (c *svgContext) Create() error {
+	if c.w == nil {
+		return errors.New("output writer is nil")
+	}
+	return nil
+}
+
+func (c *svgContext) DeleteTexture(image int) error {
+	delete(c.imageURIs, image)
+	delete(c.maskIDs, image)
+	return c.imageTextures.DeleteTexture(image)
+}
+
+func (c *svgContext) UpdateTexture(image, x, y, w, h int, data []byte) error {
+	delete(c.imageURIs, image)
+	delete(c.maskIDs, image)
+	return c.imageTextures.UpdateTexture(image, x, y, w, h, data)
+}
+
+func (c *svgContext) Viewport(width, height int) {
+	c.buf.Reset()
+	c.nextID = 0
+	c.embeddedFonts = make(map[int]bool)
+	c.clipIDs = make(map[*Clip]string)
+	c.maskIDs = make(map[int]string)
+	c.width, c.height = width, height
+	fmt.Fprintf(&c.buf, `<?xml version="1.0" encoding="UTF-8"?>`+"\n")
+	fmt.Fprintf(&c.buf, `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+}
+
+func (c *svgContext) Cancel() {
+	c.buf.Reset()
+}
+
+func (c *svgContext) Flush() {
+	if c.buf.Len() == 0 {
+		return
+	}
+	c.buf.WriteString("</svg>\n")
+	if _, err := c.w.Write(c.buf.Bytes()); err != nil {
+		log.Printf("can't write SVG document: %v\n", err)
+	}
+	c.buf.Reset()
+}
+
+func (c *svgContext) Fill(paint *Paint, scissor *Scissor, fringe float32, bounds [4]float32, paths []Path) {
+}
+
+func (c *svgContext) Stroke(paint *Paint, scissor *Scissor, fringe float32, strokeWidth float32, paths []Path) {
+}
+
+func (c *svgContext) Triangles(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+}
+
+func (c *svgContext) TriangleStrip(paint *Paint, scissor *Scissor, vertexes []Vertex) {
+}
+
+func (c *svgContext) Delete() {
+	c.textures = nil
+	c.imageURIs = nil
+}
+
+func (c *svgContext) fillPath(paint *Paint, scissor *Scissor, commands []float32) {
+	subpaths := splitSubpaths(commands, true)
+	if len(subpaths) == 0 {
+		return
+	}
+	attrs := ""
+	if c.fillRule == EvenOdd {
+		attrs = ` fill-rule="evenodd"`
+	}
+	c.drawPath(paint, scissor, svgPathData(subpaths, true), "fill", attrs)
+}
+
+func (c *svgContext) strokePath(paint *Paint, scissor *Scissor, style *nvgStrokeStyle, commands []float32) {
+	subpaths := splitSubpaths(commands, false)
+	if len(subpaths) == 0 {
+		return
+	}
+	attrs := fmt.Sprintf(` fill="none" stroke-width="%s" stroke-linecap="%s" stroke-linejoin="%s" stroke-miterlimit="%s"`,
+		svgFloat(style.width), svgLineCap(style.lineCap), svgLineJoin(style.lineJoin), svgFloat(maxF(1.0, style.miterLimit)))
+	if len(style.lineDash) > 0 {
+		dash := make([]string, len(style.lineDash))
+		for i, length := range style.lineDash {
+			dash[i] = svgFloat(length)
+		}
+		// Each subpath is drawn separately, so the pattern continues from the end of the previous subpath.
+		offset := style.dashOffset
+		for _, subpath := range subpaths {
+			dashAttrs := fmt.Sprintf(` stroke-dasharray="%s" stroke-dashoffset="%s"`, strings.Join(dash, " "), svgFloat(offset))
+			c.drawPath(paint, scissor, svgPathData([]nvgSubpath{subpath}, false), "stroke", attrs+dashAttrs)
+			offset += subpath.length()
+		}
+		return
+	}
+	c.drawPath(paint, scissor, svgPathData(subpaths, false), "stroke", attrs)
+}
+
+func (c *svgContext) text(paint *Paint, scissor *Scissor, run *nvgTextRun) {
+	if len(run.runes) == 0 {
+		return
+	}
+	fontSize := run.fontSize
+	if run.fontData != nil {
+		c.embedFont(run)
+		fontSize *= c.fontScale(run)
+	}
+	// The coordinates of the paint are in the canvas, but <text> has the transform.
+	localPaint := *paint
+	localPaint.Xform = paint.Xform.Multiply(run.xform.Inverse())
+	if paintType := vectorPaintTypeOf(&localPaint); paintType == vectorPaintBox || paintType == vectorPaintConic {
+		localPaint.Stops = nil
+		localPaint.Conic = false
+		localPaint.OuterColor = localPaint.InnerColor
+	}
+
+	blended := c.beginBlendMode()
+	masked := c.beginMask()
+	clipped := c.beginClip()
+	scissored := c.beginScissor(scissor)
+	fill := c.paintAttrs("fill", &localPaint)
+	fmt.Fprintf(&c.buf, `<text x="%s" y="%s" font-family="%s" font-size="%s"`,
+		svgFloat(run.x), svgFloat(run.y), svgEscape(run.fontName), svgFloat(fontSize))
+	if run.letterSpacing != 0 {
+		fmt.Fprintf(&c.buf, ` letter-spacing="%s"`, svgFloat(run.letterSpacing))
+	}
+	fmt.Fprintf(&c.buf, ` transform="%s" %s xml:space="preserve">%s</text>`+"\n",
+		svgMatrix(run.xform), fill, svgEscape(string(run.runes)))
+	c.endScissor(scissored)
+	c.endClip(clipped)
+	c.endMask(masked)
+	c.endBlendMode(blended)
+}
+
+func (c *svgContext) drawPath(paint *Paint, scissor *Scissor, d, property, attrs string) {
+	blended := c.beginBlendMode()
+	masked := c.beginMask()
+	clipped := c.beginClip()
+	scissored := c.beginScissor(scissor)
+	if vectorPaintTypeOf(paint) == vectorPaintBox {
+		inside, outside, pattern := c.boxGradient(paint)
+		fmt.Fprintf(&c.buf, `<path d="%s"%s %s clip-path="url(#%s)"/>`+"\n", d, attrs, svgColorAttrs(property, paint.OuterColor), outside)
+		fmt.Fprintf(&c.buf, `<path d="%s"%s %s="url(#%s)" clip-path="url(#%s)"/>`+"\n", d, attrs, property, pattern, inside)
+	} else {
+		paintAttrs := c.paintAttrs(property, paint)
+		fmt.Fprintf(&c.buf, `<path d="%s"%s %s/>`+"\n", d, attrs, paintAttrs)
+	}
+	c.endScissor(scissored)
+	c.endClip(clipped)
+	c.endMask(masked)
+	c.endBlendMode(blended)
+}
+
+func (c *svgContext) newID(prefix string) string {
+	c.nextID++
+	return prefix + strconv.Itoa(c.nextID)
+}
+
+func (c *svgContext) beginScissor(scissor *Scissor) bool {
+	if scissor.Extent[0] < -0.5 || scissor.Extent[1] < -0.5 {
+		return false
+	}
+	id := c.newID("clip")
+	ex, ey := scissor.Extent[0], scissor.Extent[1]
+	fmt.Fprintf(&c.buf, `<defs><clipPath id="%s"><rect x="%s" y="%s" width="%s" height="%s" transform="%s"/></clipPath></defs>`+"\n",
+		id, svgFloat(-ex), svgFloat(-ey), svgFloat(ex*2), svgFloat(ey*2), svgMatrix(scissor.Xform))
+	fmt.Fprintf(&c.buf, `<g clip-path="url(#%s)">`+"\n", id)
+	return true
+}
+
+func (c *svgContext) SetClip(clip *Clip) {
+	c.clip = clip
+}
+
+// beginClip opens the group clipped by the clip path. Each clip becomes <clipPath> that is clipped by its parent.
+func (c *svgContext) beginClip() bool {
+	if c.clip == nil {
+		return false
+	}
+	for _, clip := range c.clip.chain() {
+		if _, ok := c.clipIDs[clip]; ok {
+			continue
+		}
+		id := c.newID("clip")
+		var parent string
+		if clip.Parent != nil {
+			parent = fmt.Sprintf(` clip-path="url(#%s)"`, c.clipIDs[clip.Parent])
+		}
+		rule := ""
+		if clip.FillRule == EvenOdd {
+			rule = ` clip-rule="evenodd"`
+		}
+		fmt.Fprintf(&c.buf, `<defs><clipPath id="%s"%s><path d="%s"%s/></clipPath></defs>`+"\n",
+			id, parent, svgPathData(splitSubpaths(clip.commands, true), true), rule)
+		c.clipIDs[clip] = id
+	}
+	fmt.Fprintf(&c.buf, `<g clip-path="url(#%s)">`+"\n", c.clipIDs[c.clip])
+	return true
+}
+
+func (c *svgContext) endClip(clipped bool) {
+	if clipped {
+		c.buf.WriteString("</g>\n")
+	}
+}
+
+func (c *svgContext) SetMask(image int) {
+	c.mask = image
+}
+
+// beginMask opens the group masked by the alpha of the mask image. The image is stretched over the whole document.
+func (c *svgContext) beginMask() bool {
+	if c.mask == 0 {
+		return false
+	}
+	id, ok := c.maskIDs[c.mask]
+	if !ok {
+		uri := c.imageURI(c.mask)
+		if uri == "" {
+			return false
+		}
+		id = c.newID("mask")
+		fmt.Fprintf(&c.buf, `<defs><mask id="%s" maskUnits="userSpaceOnUse" x="0" y="0" width="%d" height="%d" style="mask-type:alpha"><image width="%d" height="%d" preserveAspectRatio="none" xlink:href="%s"/></mask></defs>`+"\n",
+			id, c.width, c.height, c.width, c.height, uri)
+		c.maskIDs[c.mask] = id
+	}
+	fmt.Fprintf(&c.buf, `<g mask="url(#%s)">`+"\n", id)
+	return true
+}
+
+func (c *svgContext) endMask(masked bool) {
+	if masked {
+		c.buf.WriteString("</g>\n")
+	}
+}
+
+func (c *svgContext) SetBlendMode(mode BlendMode) {
+	c.blendMode = mode
+}
+
+func (c *svgContext) SetFillRule(rule FillRule) {
+	c.fillRule = rule
+}
+
+// beginBlendMode opens the group that has mix-blend-mode.
+func (c *svgContext) beginBlendMode() bool {
+	if c.blendMode == BlendNormal {
+		return false
+	}
+	fmt.Fprintf(&c.buf, `<g style="mix-blend-mode:%s">`+"\n", svgBlendMode(c.blendMode))
+	return true
+}
+
+func (c *svgContext) endBlendMode(blended bool) {
+	if blended {
+		c.buf.WriteString("</g>\n")
+	}
+}
+
+func (c *svgContext) endScissor(scissored bool) {
+	if scissored {
+		c.buf.WriteString("</g>\n")
+	}
+}
+
+// paintAttrs writes the definitions the paint needs, and returns the attributes to use it for the property.
+func (c *svgContext) paintAttrs(property string, paint *Paint) string {
+	switch vectorPaintTypeOf(paint) {
+	case vectorPaintLinear:
+		id := c.newID("grad")
+		sx, sy, ex, ey := linearGradientPoints(paint)
+		fmt.Fprintf(&c.buf, `<defs><linearGradient id="%s" gradientUnits="userSpaceOnUse" x1="%s" y1="%s" x2="%s" y2="%s">%s</linearGradient></defs>`+"\n",
+			id, svgFloat(sx), svgFloat(sy), svgFloat(ex), svgFloat(ey), svgStops(paint.gradientStops(), 0))
+		return fmt.Sprintf(`%s="url(#%s)"`, property, id)
+	case vectorPaintRadial:
+		id := c.newID("grad")
+		radius, inner, stops := radialGradientStops(paint)
+		fmt.Fprintf(&c.buf, `<defs><radialGradient id="%s" gradientUnits="userSpaceOnUse" cx="0" cy="0" r="%s" gradientTransform="%s">%s</radialGradient></defs>`+"\n",
+			id, svgFloat(radius), svgMatrix(paint.Xform), svgStops(stops, inner))
+		return fmt.Sprintf(`%s="url(#%s)"`, property, id)
+	case vectorPaintConic:
+		return fmt.Sprintf(`%s="url(#%s)"`, property, c.conicGradient(paint))
+	case vectorPaintImage:
+		uri := c.imageURI(paint.Image)
+		if uri == "" {
+			return fmt.Sprintf(`%s="none"`, property)
+		}
+		id := c.newID("pattern")
+		w, h := paint.Extent[0], paint.Extent[1]
+		fmt.Fprintf(&c.buf, `<defs><pattern id="%s" patternUnits="userSpaceOnUse" width="%s" height="%s" patternTransform="%s"><image width="%s" height="%s" preserveAspectRatio="none" opacity="%s" xlink:href="%s"/></pattern></defs>`+"\n",
+			id, svgFloat(w), svgFloat(h), svgMatrix(paint.Xform), svgFloat(w), svgFloat(h), svgFloat(straightColor(paint.InnerColor)[3]), uri)
+		return fmt.Sprintf(`%s="url(#%s)"`, property, id)
+	}
+	return svgColorAttrs(property, paint.InnerColor)
+}
+
+// boxGradient writes the image of the box gradient as pattern, and the clip paths for inside and outside of the image.
+func (c *svgContext) boxGradient(paint *Paint) (inside, outside, pattern string) {
+	hw := paint.Extent[0] + paint.Feather*0.5
+	hh := paint.Extent[1] + paint.Feather*0.5
+	scale := paint.Xform.getAverageScale()
+	pw := clampI(int(math.Ceil(float64(hw*2*scale))), 1, 256)
+	ph := clampI(int(math.Ceil(float64(hh*2*scale))), 1, 256)
+	img := image.NewRGBA(image.Rect(0, 0, pw, ph))
+	for y := 0; y < ph; y++ {
+		for x := 0; x < pw; x++ {
+			px := -hw + (float32(x)+0.5)*hw*2/float32(pw)
+			py := -hh + (float32(y)+0.5)*hh*2/float32(ph)
+			img.Set(x, y, boxGradientColor(paint, px, py))
+		}
+	}
+	inside = c.newID("clip")
+	outside = c.newID("clip")
+	pattern = c.newID("pattern")
+	xform := svgMatrix(paint.Xform)
+	large := svgFloat(nvgGradientLarge)
+	fmt.Fprintf(&c.buf, `<defs><clipPath id="%s"><rect x="%s" y="%s" width="%s" height="%s" transform="%s"/></clipPath>`,
+		inside, svgFloat(-hw), svgFloat(-hh), svgFloat(hw*2), svgFloat(hh*2), xform)
+	fmt.Fprintf(&c.buf, `<clipPath id="%s"><path d="M-%s -%sH%sV%sH-%sZM%s %sV%sH%sV%sZ" clip-rule="evenodd" transform="%s"/></clipPath>`,
+		outside, large, large, large, large, large, svgFloat(-hw), svgFloat(-hh), svgFloat(hh), svgFloat(hw), svgFloat(-hh), xform)
+	fmt.Fprintf(&c.buf, `<pattern id="%s" patternUnits="userSpaceOnUse" x="%s" y="%s" width="%s" height="%s" patternTransform="%s"><image x="%s" y="%s" width="%s" height="%s" preserveAspectRatio="none" xlink:href="%s"/></pattern></defs>`+"\n",
+		pattern, svgFloat(-hw), svgFloat(-hh), svgFloat(hw*2), svgFloat(hh*2), xform,
+		svgFloat(-hw), svgFloat(-hh), svgFloat(hw*2), svgFloat(hh*2), pngDataURI(img))
+	return
+}
+
+// conicGradient writes the image of the conic gradient over the document as pattern.
+func (c *svgContext) conicGradient(paint *Paint) string {
+	pw := clampI(c.width, 1, 512)
+	ph := clampI(c.height, 1, 512)
+	img := image.NewRGBA(image.Rect(0, 0, pw, ph))
+	inverse := paint.Xform.Inverse()
+	stops := paint.gradientStops()
+	for y := 0; y < ph; y++ {
+		for x := 0; x < pw; x++ {
+			px, py := inverse.TransformPoint((float32(x)+0.5)*float32(c.width)/float32(pw), (float32(y)+0.5)*float32(c.height)/float32(ph))
+			img.Set(x, y, stops.colorAt(conicOffset(px, py)))
+		}
+	}
+	pattern := c.newID("pattern")
+	fmt.Fprintf(&c.buf, `<defs><pattern id="%s" patternUnits="userSpaceOnUse" width="%d" height="%d"><image width="%d" height="%d" preserveAspectRatio="none" xlink:href="%s"/></pattern></defs>`+"\n",
+		pattern, c.width, c.height, c.width, c.height, pngDataURI(img))
+	return pattern
+}
+
+func (c *svgContext) imageURI(image int) string {
+	if uri, ok := c.imageURIs[image]; ok {
+		return uri
+	}
+	tex := c.findTexture(image)
+	if tex == nil {
+		return ""
+	}
+	uri := pngDataURI(tex.toImage())
+	c.imageURIs[image] = uri
+	return uri
+}
+
+func (c *svgContext) embedFont(run *nvgTextRun) {
+	if c.embeddedFonts[run.fontID] {
+		return
+	}
+	c.embeddedFonts[run.fontID] = true
+	css := fmt.Sprintf(`@font-face{font-family:%s;src:url(data:font/ttf;base64,%s);}`,
+		strconv.Quote(run.fontName), base64.StdEncoding.EncodeToString(run.fontData))
+	fmt.Fprintf(&c.buf, `<defs><style type="text/css">%s</style></defs>`+"\n", svgEscape(css))
+}
+
+// fontScale returns the ratio of the em size to the font size. Font size of NanoVGo is the height from
+// the descender to the ascender.
+func (c *svgContext) fontScale(run *nvgTextRun) float32 {
+	if scale, ok := c.fontScales[run.fontID]; ok {
+		return scale
+	}
+	var scale float32 = 1.0
+	if font, err := truetype.InitFont(run.fontData, 0); err == nil {
+		scale = float32(font.ScaleForPixelHeight(1.0) / font.ScaleForMappingEmToPixels(1.0))
+	}
+	c.fontScales[run.fontID] = scale
+	return scale
+}
+
+func svgPathData(subpaths []nvgSubpath, fill bool) string {
+	var buf bytes.Buffer
+	for _, subpath := range subpaths {
+		fmt.Fprintf(&buf, "M%s %s", svgFloat(subpath.x), svgFloat(subpath.y))
+		for _, segment := range subpath.segments {
+			pts := segment.points
+			if segment.command == nvgBEZIERTO {
+				fmt.Fprintf(&buf, "C%s %s %s %s %s %s", svgFloat(pts[0]), svgFloat(pts[1]), svgFloat(pts[2]), svgFloat(pts[3]), svgFloat(pts[4]), svgFloat(pts[5]))
+			} else {
+				fmt.Fprintf(&buf, "L%s %s", svgFloat(pts[0]), svgFloat(pts[1]))
+			}
+		}
+		if fill || subpath.closed {
+			buf.WriteString("Z")
+		}
+	}
+	return buf.String()
+}
+
+func svgColorAttrs(property string, c color.Color) string {
+	rgba := straightColor(c)
+	attrs := fmt.Sprintf(`%s="#%02x%02x%02x"`, property, uint8(rgba[0]*255+0.5), uint8(rgba[1]*255+0.5), uint8(rgba[2]*255+0.5))
+	if rgba[3] < 1.0 {
+		attrs += fmt.Sprintf(` %s-opacity="%s"`, property, svgFloat(rgba[3]))
+	}
+	return attrs
+}
+
+// svgStops returns <stop> elements of the stops. The offsets of the stops are mapped into [start, 1].
+func svgStops(stops GradientStops, start float32) string {
+	var buf bytes.Buffer
+	for _, stop := range stops {
+		buf.WriteString(svgStop(start+stop.Offset*(1-start), stop.Color))
+	}
+	return buf.String()
+}
+
+func svgStop(offset float32, c color.Color) string {
+	rgba := straightColor(c)
+	return fmt.Sprintf(`<stop offset="%s" stop-color="#%02x%02x%02x" stop-opacity="%s"/>`,
+		svgFloat(offset), uint8(rgba[0]*255+0.5), uint8(rgba[1]*255+0.5), uint8(rgba[2]*255+0.5), svgFloat(rgba[3]))
+}
+
+func svgMatrix(t TransformMatrix) string {
+	return fmt.Sprintf("matrix(%s %s %s %s %s %s)", svgFloat(t[0]), svgFloat(t[1]), svgFloat(t[2]), svgFloat(t[3]), svgFloat(t[4]), svgFloat(t[5]))
+}
+
+func svgFloat(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+func svgEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func svgLineCap(lineCap LineCap) string {
+	switch lineCap {
+	case Round:
+		return "round"
+	case Square:
+		return "square"
+	}
+	return "butt"
+}
+
+func svgLineJoin(lineJoin LineCap) string {
+	switch lineJoin {
+	case Round:
+		return "round"
+	case Bevel:
+		return "bevel"
+	}
+	return "miter"
+}
+
+func svgBlendMode(mode BlendMode) string {
+	switch mode {
+	case BlendMultiply:
+		return "multiply"
+	case BlendScreen:
+		return "screen"
+	case BlendOverlay:
+		return "overlay"
+	case BlendDarken:
+		return "darken"
+	case BlendLighten:
+		return "lighten"
+	case BlendColorDodge:
+		return "color-dodge"
+	case BlendDifference:
+		return "difference"
+	}
+	return "normal"
+}
+
+func pngDataURI(img image.Image) string {
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+}
@@ -0,0 +1,130 @@
+package nanovgo
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/color"
+	"io"
+	"strings"
+	"testing"
+)
+
+func renderSVG(t *testing.T, draw func(ctx *Context)) string {
+	var buf bytes.Buffer
+	ctx, err := NewSVGContext(&buf)
+	if err != nil {
+		t.Fatalf("NewSVGContext() failed: %v", err)
+	}
+	ctx.BeginFrame(100, 100, 1.0)
+	draw(ctx)
+	ctx.EndFrame()
+
+	decoder := xml.NewDecoder(strings.NewReader(buf.String()))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("SVG document is not well-formed: %v\n%s", err, buf.String())
+		}
+	}
+	return buf.String()
+}
+
+func TestSVGContextFill(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 20, 20)
+		ctx.SetFillColor(color.RGBA{R: 255, A: 255})
+		ctx.Fill()
+	})
+	if !strings.Contains(svg, `<path d="M10 10L10 30L30 30L30 10Z" fill="#ff0000"/>`) {
+		t.Errorf("SVG should contain the filled rectangle:\n%s", svg)
+	}
+}
+
+func TestSVGContextHole(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(10, 10, 20, 20)
+		ctx.Rect(15, 15, 10, 10)
+		ctx.PathWinding(Hole)
+		ctx.Fill()
+	})
+	// The hole should be reversed to make a hole with the nonzero fill rule.
+	if !strings.Contains(svg, `d="M10 10L10 30L30 30L30 10ZM25 15L25 25L15 25L15 15Z"`) {
+		t.Errorf("SVG should contain the reversed hole:\n%s", svg)
+	}
+}
+
+func TestSVGContextStroke(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.Scale(2, 2)
+		ctx.BeginPath()
+		ctx.MoveTo(10, 10)
+		ctx.LineTo(20, 10)
+		ctx.SetStrokeWidth(3)
+		ctx.SetLineCap(Round)
+		ctx.SetStrokeColor(color.NRGBA{B: 255, A: 128})
+		ctx.Stroke()
+	})
+	if !strings.Contains(svg, `<path d="M20 20L40 20" fill="none" stroke-width="6" stroke-linecap="round" stroke-linejoin="miter" stroke-miterlimit="10" stroke="#0000ff" stroke-opacity="0.5`) {
+		t.Errorf("SVG should contain the stroke:\n%s", svg)
+	}
+}
+
+func TestSVGContextGradientAndScissor(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.Scissor(0, 0, 50, 100)
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 100, 100)
+		ctx.SetFillPaint(LinearGradient(0, 0, 100, 0, color.RGBA{A: 255}, color.RGBA{R: 255, G: 255, B: 255, A: 255}))
+		ctx.Fill()
+		ctx.SetFillPaint(RadialGradient(50, 50, 10, 20, color.RGBA{A: 255}, color.RGBA{R: 255, A: 255}))
+		ctx.Fill()
+	})
+	for _, expected := range []string{
+		`<clipPath id="clip1"><rect x="-25" y="-50" width="50" height="100" transform="matrix(1 0 0 1 25 50)"/></clipPath>`,
+		`<g clip-path="url(#clip1)">`,
+		`<linearGradient id="grad2" gradientUnits="userSpaceOnUse" x1="0" y1="0" x2="100" y2="0">`,
+		`<radialGradient id="grad4" gradientUnits="userSpaceOnUse" cx="0" cy="0" r="20" gradientTransform="matrix(1 0 0 1 50 50)"><stop offset="0.5"`,
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("SVG should contain %s:\n%s", expected, svg)
+		}
+	}
+}
+
+func TestSVGContextText(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		if ctx.CreateFont("sans", "sample/Roboto-Regular.ttf") == -1 {
+			t.Skip("font file is not available")
+		}
+		ctx.SetFontFace("sans")
+		ctx.SetFontSize(20)
+		ctx.Text(10, 50, "a<b")
+	})
+	if !strings.Contains(svg, `@font-face{font-family:&#34;sans&#34;;src:url(data:font/ttf;base64,`) {
+		t.Error("SVG should embed the font")
+	}
+	if !strings.Contains(svg, `<text x="10" y="50" font-family="sans"`) || !strings.Contains(svg, `>a&lt;b</text>`) {
+		t.Errorf("SVG should contain the text element:\n%s", svg)
+	}
+}
+
+func TestSVGContextCancelFrame(t *testing.T) {
+	svg := renderSVG(t, func(ctx *Context) {
+		ctx.BeginPath()
+		ctx.Rect(0, 0, 10, 10)
+		ctx.Fill()
+		ctx.CancelFrame()
+
+		ctx.BeginFrame(100, 100, 1.0)
+		ctx.BeginPath()
+		ctx.Circle(50, 50, 10)
+		ctx.Fill()
+	})
+	if strings.Count(svg, "<svg ") != 1 || strings.Count(svg, "<path ") != 1 {
+		t.Errorf("CancelFrame() should discard the frame:\n%s", svg)
+	}
+}
@@ -0,0 +1,40 @@
+package nanovgo
+
+import (
+	"testing"
+)
+
+func nearlyEqual(a, b float32) bool {
+	return absF(a-b) < 1e-4
+}
+
+func TestTransformPoint(t *testing.T) {
+	cases := []struct {
+		name   string
+		xform  TransformMatrix
+		x, y   float32
+		ex, ey float32
+	}{
+		{"translate", TranslateMatrix(10, 20), 1, 2, 11, 22},
+		{"scale", ScaleMatrix(2, 3), 1, 2, 2, 6},
+		{"rotate", RotateMatrix(PI / 2), 1, 0, 0, 1},
+		{"skew-x", SkewXMatrix(PI / 4), 0, 1, 1, 1},
+		{"skew-y", SkewYMatrix(PI / 4), 1, 0, 1, 1},
+		{"rotate then translate", RotateMatrix(PI / 2).Multiply(TranslateMatrix(5, 0)), 1, 0, 5, 1},
+	}
+	for _, c := range cases {
+		x, y := c.xform.TransformPoint(c.x, c.y)
+		if !nearlyEqual(x, c.ex) || !nearlyEqual(y, c.ey) {
+			t.Errorf("%s: (%f, %f) should be transformed to (%f, %f), but (%f, %f)", c.name, c.x, c.y, c.ex, c.ey, x, y)
+		}
+	}
+}
+
+func TestTransformInverse(t *testing.T) {
+	xform := RotateMatrix(0.3).Multiply(ScaleMatrix(2, 4)).Multiply(TranslateMatrix(10, -5))
+	x, y := xform.TransformPoint(3, 7)
+	x, y = xform.Inverse().TransformPoint(x, y)
+	if !nearlyEqual(x, 3) || !nearlyEqual(y, 7) {
+		t.Errorf("inverse transform should return original point (3, 7), but (%f, %f)", x, y)
+	}
+}
@@ -55,6 +55,13 @@ func maxF(a, b float32) float32 {
 	return b
 }
 
+func minI(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func maxI(a, b int) int {
 	if a > b {
 		return a
@@ -84,6 +91,14 @@ func acosF(a float32) float32 {
 	return float32(math.Acos(float64(a)))
 }
 
+func sinF(a float32) float32 {
+	return float32(math.Sin(float64(a)))
+}
+
+func cosF(a float32) float32 {
+	return float32(math.Cos(float64(a)))
+}
+
 func tanF(a float32) float32 {
 	return float32(math.Tan(float64(a)))
 }
@@ -132,10 +147,11 @@ func distPtSeg(x, y, px, py, qx, qy float32) float32 {
 	dx := x - px
 	dy := y - py
 	d := pqx*pqx + pqy*pqy
-	t := clampF(pqx*dx+pqy*dy, 0.0, 1.1)
+	t := pqx*dx + pqy*dy
 	if d > 0 {
 		t /= d
 	}
+	t = clampF(t, 0.0, 1.0)
 	dx = px + t*pqx - x
 	dy = py + t*pqy - y
 	return dx*dx + dy*dy
@@ -175,6 +191,15 @@ func curveDivs(r, arc, tol float32) int {
 	return maxI(2, int(math.Ceil(float64(arc)/da)))
 }
 
+// arcBezierError estimates the maximum radial error of a cubic bezier approximating a circle arc
+// of radius r and sweep angle a.
+func arcBezierError(r, a float32) float32 {
+	s := sinF(absF(a) / 4.0)
+	c := cosF(a / 4.0)
+	s2 := s * s
+	return r * 4.0 / 27.0 * s2 * s2 * s2 / (c * c)
+}
+
 func chooseBevel(bevel bool, p0, p1 *nvgPoint, w float32) (x0, y0, x1, y1 float32) {
 	if bevel {
 		x0 = p1.x + p0.dy*w
@@ -190,7 +215,7 @@ func chooseBevel(bevel bool, p0, p1 *nvgPoint, w float32) (x0, y0, x1, y1 float3
 	return
 }
 
-func roundJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru float32, nCap int, fringe float32) int {
+func roundJoin(dst []Vertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru float32, nCap int, fringe float32) int {
 	dlx0 := p0.dy
 	dly0 := -p0.dx
 	dlx1 := p1.dy
@@ -248,7 +273,7 @@ func roundJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru floa
 	return index
 }
 
-func bevelJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fringe float32) int {
+func bevelJoin(dst []Vertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fringe float32) int {
 	dlx0 := p0.dy
 	dly0 := -p0.dx
 	dlx1 := p1.dy
@@ -325,7 +350,7 @@ func bevelJoin(dst []nvgVertex, index int, p0, p1 *nvgPoint, lw, rw, lu, ru, fri
 	return index
 }
 
-func buttCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
+func buttCapStart(dst []Vertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
 	px := p.x - dx*d
 	py := p.y - dy*d
 	dlx := dy
@@ -337,7 +362,7 @@ func buttCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa floa
 	return index + 4
 }
 
-func buttCapEnd(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
+func buttCapEnd(dst []Vertex, index int, p *nvgPoint, dx, dy, w, d, aa float32) int {
 	px := p.x + dx*d
 	py := p.y + dy*d
 	dlx := dy
@@ -345,11 +370,11 @@ func buttCapEnd(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w, d, aa float3
 	(&dst[index]).set(px+dlx*w, py+dly*w, 0, 1)
 	(&dst[index+1]).set(px-dlx*w, py-dly*w, 1, 1)
 	(&dst[index+2]).set(px+dlx*w+dx*aa, py+dly*w+dy*aa, 0, 0)
-	(&dst[index+3]).set(px-dlx*w+dx*aa, py-dly*w-dy*aa, 1, 0)
+	(&dst[index+3]).set(px-dlx*w+dx*aa, py-dly*w+dy*aa, 1, 0)
 	return index + 4
 }
 
-func roundCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
+func roundCapStart(dst []Vertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
 	px := p.x
 	py := p.y
 	dlx := dy
@@ -368,7 +393,7 @@ func roundCapStart(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w float32, n
 	return index + 2
 }
 
-func roundCapEnd(dst []nvgVertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
+func roundCapEnd(dst []Vertex, index int, p *nvgPoint, dx, dy, w float32, nCap int, aa float32) int {
 	px := p.x
 	py := p.y
 	dlx := dy
@@ -409,3 +434,15 @@ func nearestPow2(num int) int {
 func quantize(a, d float32) float32 {
 	return float32(int(a/d+0.5)) * d
 }
+
+// scaleDash returns the dash pattern scaled by the scale of the transform.
+func scaleDash(dash []float32, scale float32) []float32 {
+	if len(dash) == 0 {
+		return nil
+	}
+	scaled := make([]float32, len(dash))
+	for i, length := range dash {
+		scaled[i] = length * scale
+	}
+	return scaled
+}
@@ -0,0 +1,284 @@
+package nanovgo
+
+import (
+	"image/color"
+)
+
+// vectorRenderer is implemented by the renderers that write vector documents.
+// Context passes the path commands and the text runs to it instead of the tessellated vertexes,
+// so the output keeps curves and doesn't depend on the resolution.
+type vectorRenderer interface {
+	fillPath(paint *Paint, scissor *Scissor, commands []float32)
+	strokePath(paint *Paint, scissor *Scissor, style *nvgStrokeStyle, commands []float32)
+	text(paint *Paint, scissor *Scissor, run *nvgTextRun)
+}
+
+// nvgStrokeStyle holds the stroke parameters. Width is in the canvas coordinates.
+type nvgStrokeStyle struct {
+	width      float32
+	lineCap    LineCap
+	lineJoin   LineCap
+	miterLimit float32
+	lineDash   []float32
+	dashOffset float32
+}
+
+// nvgTextRun is a text passed to vectorRenderer. x and y are the left end of the baseline in the local coordinates
+// after the text align is applied.
+type nvgTextRun struct {
+	xform         TransformMatrix
+	fontID        int
+	fontName      string
+	fontData      []byte
+	fontSize      float32
+	letterSpacing float32
+	x, y          float32
+	runes         []rune
+}
+
+type nvgSegment struct {
+	command nvgCommands
+	// nvgLINETO uses points[0:2], nvgBEZIERTO uses two control points and the end point.
+	points [6]float32
+}
+
+func (s *nvgSegment) end() (float32, float32) {
+	if s.command == nvgBEZIERTO {
+		return s.points[4], s.points[5]
+	}
+	return s.points[0], s.points[1]
+}
+
+type nvgSubpath struct {
+	x, y     float32
+	segments []nvgSegment
+	closed   bool
+	winding  Winding
+}
+
+// polyline returns the points of the subpath. Bezier curves are approximated by lines.
+func (p *nvgSubpath) polyline() []nvgPoint {
+	points := []nvgPoint{{x: p.x, y: p.y}}
+	x0, y0 := p.x, p.y
+	for i := range p.segments {
+		segment := &p.segments[i]
+		if segment.command == nvgBEZIERTO {
+			pts := &segment.points
+			for j := 1; j <= 8; j++ {
+				t := float32(j) / 8
+				it := 1 - t
+				x := it*it*it*x0 + 3*it*it*t*pts[0] + 3*it*t*t*pts[2] + t*t*t*pts[4]
+				y := it*it*it*y0 + 3*it*it*t*pts[1] + 3*it*t*t*pts[3] + t*t*t*pts[5]
+				points = append(points, nvgPoint{x: x, y: y})
+			}
+		} else {
+			points = append(points, nvgPoint{x: segment.points[0], y: segment.points[1]})
+		}
+		x0, y0 = segment.end()
+	}
+	return points
+}
+
+// area returns the signed area of the subpath.
+func (p *nvgSubpath) area() float32 {
+	points := p.polyline()
+	if len(points) < 3 {
+		return 0
+	}
+	return polyArea(points, len(points))
+}
+
+// length returns the length of the subpath. Closed subpaths include the segment back to the start.
+func (p *nvgSubpath) length() float32 {
+	points := p.polyline()
+	if p.closed {
+		points = append(points, points[0])
+	}
+	var length float32
+	for i := 1; i < len(points); i++ {
+		dx, dy := points[i].x-points[i-1].x, points[i].y-points[i-1].y
+		length += sqrtF(dx*dx + dy*dy)
+	}
+	return length
+}
+
+// reverse reverses the direction of the subpath.
+func (p *nvgSubpath) reverse() {
+	n := len(p.segments)
+	if n == 0 {
+		return
+	}
+	segments := make([]nvgSegment, 0, n)
+	x, y := p.segments[n-1].end()
+	for i := n - 1; i >= 0; i-- {
+		prevX, prevY := p.x, p.y
+		if i > 0 {
+			prevX, prevY = p.segments[i-1].end()
+		}
+		segment := p.segments[i]
+		if segment.command == nvgBEZIERTO {
+			pts := segment.points
+			segment.points = [6]float32{pts[2], pts[3], pts[0], pts[1], prevX, prevY}
+		} else {
+			segment.points[0], segment.points[1] = prevX, prevY
+		}
+		segments = append(segments, segment)
+	}
+	p.x, p.y = x, y
+	p.segments = segments
+}
+
+// splitSubpaths converts the path commands into subpaths. If fill is true, the direction of each subpath is
+// enforced by its winding like Context.flattenPaths(), so the subpaths can be filled with the nonzero rule.
+// Strokes keep the order of the points, so dashes start where the path starts.
+func splitSubpaths(commands []float32, fill bool) []nvgSubpath {
+	var subpaths []nvgSubpath
+	var last *nvgSubpath
+	i := 0
+	for i < len(commands) {
+		switch nvgCommands(commands[i]) {
+		case nvgMOVETO:
+			subpaths = append(subpaths, nvgSubpath{x: commands[i+1], y: commands[i+2], winding: Solid})
+			last = &subpaths[len(subpaths)-1]
+			i += 3
+		case nvgLINETO:
+			if last != nil {
+				segment := nvgSegment{command: nvgLINETO}
+				copy(segment.points[:2], commands[i+1:i+3])
+				last.segments = append(last.segments, segment)
+			}
+			i += 3
+		case nvgBEZIERTO:
+			if last != nil {
+				segment := nvgSegment{command: nvgBEZIERTO}
+				copy(segment.points[:], commands[i+1:i+7])
+				last.segments = append(last.segments, segment)
+			}
+			i += 7
+		case nvgCLOSE:
+			if last != nil {
+				last.closed = true
+			}
+			i++
+		case nvgWINDING:
+			if last != nil {
+				last.winding = Winding(commands[i+1])
+			}
+			i += 2
+		default:
+			i++
+		}
+	}
+	if !fill {
+		return subpaths
+	}
+	for i := range subpaths {
+		subpath := &subpaths[i]
+		area := subpath.area()
+		if subpath.winding == Solid && area < 0.0 {
+			subpath.reverse()
+		} else if subpath.winding == Hole && area > 0.0 {
+			subpath.reverse()
+		}
+	}
+	return subpaths
+}
+
+type vectorPaintType int
+
+const (
+	vectorPaintColor vectorPaintType = iota
+	vectorPaintLinear
+	vectorPaintRadial
+	vectorPaintBox
+	vectorPaintImage
+	vectorPaintConic
+)
+
+// vectorPaintTypeOf detects the kind of the paint from its parameters.
+// The parameters of LinearGradient() and RadialGradient() are special cases of the box gradient that vector formats can express.
+func vectorPaintTypeOf(paint *Paint) vectorPaintType {
+	switch {
+	case paint.Image != 0:
+		return vectorPaintImage
+	case paint.Conic:
+		return vectorPaintConic
+	case len(paint.Stops) == 0 && sameColor(paint.InnerColor, paint.OuterColor):
+		return vectorPaintColor
+	case paint.Extent[0] >= nvgGradientLarge && paint.Radius == 0:
+		return vectorPaintLinear
+	case paint.Extent[0] == paint.Extent[1] && paint.Extent[0] == paint.Radius:
+		return vectorPaintRadial
+	}
+	return vectorPaintBox
+}
+
+// linearGradientPoints returns the start and the end points of the linear gradient in the canvas coordinates.
+func linearGradientPoints(paint *Paint) (sx, sy, ex, ey float32) {
+	sx, sy = paint.Xform.TransformPoint(0, paint.Extent[1]-paint.Feather*0.5)
+	ex, ey = paint.Xform.TransformPoint(0, paint.Extent[1]+paint.Feather*0.5)
+	return
+}
+
+// radialGradientStops returns the outer radius in the paint coordinates, the inner radius relative to it, and
+// the stops between the inner and the outer radius. The inner radius is moved to the center if it is negative.
+func radialGradientStops(paint *Paint) (radius, inner float32, stops GradientStops) {
+	inr := paint.Radius - paint.Feather*0.5
+	radius = paint.Radius + paint.Feather*0.5
+	stops = paint.gradientStops()
+	if inr < 0 {
+		// Cut the stops inside of the center.
+		clipped := GradientStops{{Offset: 0, Color: stops.colorAt(-inr / paint.Feather)}}
+		for _, stop := range stops {
+			if offset := (stop.Offset*paint.Feather + inr) / radius; offset > 0 {
+				clipped = append(clipped, GradientStop{Offset: offset, Color: stop.Color})
+			}
+		}
+		return radius, 0, clipped
+	}
+	return radius, inr / radius, stops
+}
+
+// boxGradientColor returns the color of the box gradient at the point in the paint coordinates.
+func boxGradientColor(paint *Paint, x, y float32) color.Color {
+	d := clampF((sdRoundRect(x, y, paint.Extent[0], paint.Extent[1], paint.Radius)+paint.Feather*0.5)/paint.Feather, 0.0, 1.0)
+	return paint.gradientStops().colorAt(d)
+}
+
+// sameAlpha returns true if all stops of the gradient have the same alpha.
+func sameAlpha(stops GradientStops) bool {
+	_, _, _, a := stops[0].Color.RGBA()
+	for _, stop := range stops[1:] {
+		if _, _, _, a2 := stop.Color.RGBA(); a2 != a {
+			return false
+		}
+	}
+	return true
+}
+
+func sameColor(c1, c2 color.Color) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+func mixColor(c1, c2 color.Color, t float32) color.Color {
+	a1 := colorToArray(c1)
+	a2 := colorToArray(c2)
+	var mixed [4]uint16
+	for i := range mixed {
+		mixed[i] = uint16((a1[i]*(1-t) + a2[i]*t) * 0xffff)
+	}
+	return color.RGBA64{R: mixed[0], G: mixed[1], B: mixed[2], A: mixed[3]}
+}
+
+// straightColor returns non alpha-premultiplied color components in the range of [0, 1].
+func straightColor(c color.Color) [4]float32 {
+	rgba := colorToArray(c)
+	if rgba[3] > 0 {
+		rgba[0] /= rgba[3]
+		rgba[1] /= rgba[3]
+		rgba[2] /= rgba[3]
+	}
+	return rgba
+}